@@ -6,6 +6,7 @@ import (
 
 	"genny/pkg/cli"
 	"genny/pkg/orchestrator"
+	"genny/pkg/site"
 
 	"github.com/toolvox/utilgo/pkg/errs"
 )
@@ -28,10 +29,21 @@ func main() {
 	}
 
 	// Create orchestrator
-	orch := orchestrator.NewOrchestrator(".", config.Verbose)
+	orch := orchestrator.NewOrchestrator(".", site.Options{
+		Verbose: config.Verbose,
+		Drafts:  config.Drafts,
+		Strict:  config.Strict,
+		Check:   config.Check,
+		Env:     config.Env,
+		Version: version,
+	})
 
 	// Run in appropriate mode
-	if config.Watch {
+	if config.Check {
+		if err := orch.RunCheck(); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	} else if config.Watch {
 		if err := orch.RunContinuous(); err != nil {
 			log.Fatalf("Error: %v", err)
 		}
@@ -2,15 +2,22 @@ module genny
 
 go 1.24.5
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/MakeNowJust/heredoc/v2 v2.0.1 // indirect
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
+	github.com/dlclark/regexp2 v1.12.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 )
 
 require (
-	github.com/toolvox/utilgo v0.0.5 // indirect
+	github.com/BurntSushi/toml v1.6.0
+	github.com/alecthomas/chroma/v2 v2.24.1
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
+	github.com/toolvox/utilgo v0.0.5
+	github.com/yuin/goldmark v1.8.2
 	golang.org/x/net v0.47.0
+	golang.org/x/text v0.31.0
 )
@@ -1,5 +1,5 @@
 // Package cli handles command-line interface argument parsing.
-// It supports flags for watch mode, verbose logging, and specifying the project path.
+// It supports flags for watch mode, verbose logging, publishing drafts, strict templates, environments, and specifying the project path.
 package cli
 
 import (
@@ -14,6 +14,10 @@ type Config struct {
 	RootPath string
 	Watch    bool
 	Verbose  bool
+	Drafts   bool
+	Strict   bool
+	Env      string
+	Check    bool
 }
 
 // ParseArgs parses command line arguments
@@ -25,6 +29,10 @@ func ParseArgs() (*Config, error) {
 	watchShort := flag.Bool("w", false, "Watch for file changes (shorthand)")
 	verbose := flag.Bool("verbose", false, "Enable verbose logging")
 	verboseShort := flag.Bool("v", false, "Enable verbose logging (shorthand)")
+	drafts := flag.Bool("drafts", false, "Publish drafts and pages scheduled for the future")
+	strict := flag.Bool("strict", true, "Fail the build when a template reads missing data (default on, except in watch mode)")
+	env := flag.String("env", "", "Environment to build for, selecting data overlay files (e.g. production)")
+	check := flag.Bool("check", false, "Validate the project (data schemas, pages, templates) without generating output")
 	help := flag.Bool("help", false, "Show help message")
 	helpShort := flag.Bool("h", false, "Show help message (shorthand)")
 
@@ -44,6 +52,26 @@ func ParseArgs() (*Config, error) {
 	config.Verbose = *verbose || *verboseShort
 	log.Printf("verbose: %t", config.Verbose)
 
+	// Set drafts mode
+	config.Drafts = *drafts
+	log.Printf("drafts: %t", config.Drafts)
+
+	// Set strict mode: on for production builds, off while watching unless asked for
+	config.Strict = *strict && !config.Watch
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "strict" {
+			config.Strict = *strict
+		}
+	})
+	log.Printf("strict: %t", config.Strict)
+
+	// Set environment
+	config.Env = *env
+	log.Printf("env: %q", config.Env)
+
+	// Set check mode
+	config.Check = *check
+
 	// Get root path from positional argument or use current directory
 	args := flag.Args()
 	if len(args) > 0 {
@@ -72,6 +100,10 @@ func PrintUsage() {
 	fmt.Println("Flags:")
 	fmt.Println("  -w, -watch    Watch for file changes and regenerate automatically")
 	fmt.Println("  -v, -verbose  Enable verbose logging")
+	fmt.Println("  -drafts       Publish drafts and pages scheduled for the future")
+	fmt.Println("  -strict       Fail when a template reads missing data (default on, off in watch mode)")
+	fmt.Println("  -env NAME     Build for an environment, merging data/*.NAME.yaml overlays")
+	fmt.Println("  -check        Validate the project without generating output")
 	fmt.Println("  -h, -help     Show this help message")
 	fmt.Println()
 	fmt.Println("Examples:")
@@ -79,4 +111,7 @@ func PrintUsage() {
 	fmt.Println("  genny ./mysite         # Generate site in ./mysite")
 	fmt.Println("  genny -w               # Generate and watch for changes")
 	fmt.Println("  genny -w -v ./mysite   # Generate, watch, and show verbose output")
+	fmt.Println("  genny -env production  # Build with data/*.production.* overlays")
+	fmt.Println("  genny -strict=false    # Build, rendering missing data as empty")
+	fmt.Println("  genny -check           # Report data schema violations and other errors")
 }
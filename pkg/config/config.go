@@ -0,0 +1,105 @@
+// Package config defines the optional project configuration.
+// It is read from genny.yaml at the project root; every setting has a usable default,
+// so a project without the file behaves exactly as before.
+package config
+
+// FileName is the name of the project configuration file at the project root
+const FileName = "genny.yaml"
+
+// Config holds the project configuration
+type Config struct {
+	// Taxonomies maps a front matter key (e.g. "tags") to its index page configuration
+	Taxonomies map[string]Taxonomy `yaml:"taxonomies"`
+
+	// Schemas maps a data namespace (e.g. "projects" or "blog.authors") to a JSON Schema
+	// file, relative to the project root. Sibling *.schema.json files need no mapping.
+	Schemas map[string]string `yaml:"schemas"`
+
+	// Env lists the environment variables templates may read as .Env. Variables not
+	// listed here are never exposed.
+	Env []string `yaml:"env"`
+
+	// Collections declares collections derived from loaded data (sorted, filtered,
+	// grouped lists), exposed under the .collections namespace
+	Collections map[string]Collection `yaml:"collections"`
+
+	// Highlight enables build-time syntax highlighting of code blocks. Without it, code
+	// blocks are left for client-side highlighters.
+	Highlight *Highlight `yaml:"highlight"`
+
+	// SummaryWords is the number of words in page summaries taken from the start of the
+	// page text (defaults to 50)
+	SummaryWords int `yaml:"summaryWords"`
+
+	// API exports data namespaces as JSON files under www/api/
+	API *API `yaml:"api"`
+
+	// Environments names the environments that data overlay files can target, in
+	// addition to the common ones (development, staging, production...)
+	Environments []string `yaml:"environments"`
+
+	// BaseURL is the absolute URL the site is deployed at (e.g. "https://example.com/").
+	// With it, sitemap.xml and robots.txt are generated.
+	BaseURL string `yaml:"baseURL"`
+}
+
+// Taxonomy configures the index pages generated for one front matter key
+type Taxonomy struct {
+	// Path is the output directory of the taxonomy's pages (defaults to the taxonomy name)
+	Path string `yaml:"path"`
+
+	// ListTemplate is the page template for the listing of all terms (e.g. /tags/)
+	ListTemplate string `yaml:"list"`
+
+	// TermTemplate is the page template for each term's page (e.g. /tags/go/)
+	TermTemplate string `yaml:"term"`
+}
+
+// Collection describes a collection derived from a list in the loaded data
+type Collection struct {
+	// From is the data path of the source list (e.g. "projects.items")
+	From string `yaml:"from"`
+
+	// Filter keeps items whose fields have these values (list fields must contain them)
+	Filter map[string]interface{} `yaml:"filter"`
+
+	// Sort is the field to sort by, ascending
+	Sort string `yaml:"sort"`
+
+	// Reverse reverses the order after sorting
+	Reverse bool `yaml:"reverse"`
+
+	// Limit keeps at most this many items (0 keeps all)
+	Limit int `yaml:"limit"`
+
+	// GroupBy groups the items by a field into a list of {key, items}
+	GroupBy string `yaml:"groupBy"`
+}
+
+// Highlight configures build-time syntax highlighting
+type Highlight struct {
+	// Theme is the chroma style of the generated highlight.css (defaults to "github")
+	Theme string `yaml:"theme"`
+
+	// LineNumbers numbers the lines of every highlighted block
+	LineNumbers bool `yaml:"lineNumbers"`
+}
+
+// API configures the JSON export of data namespaces
+type API struct {
+	// Namespaces lists the data namespaces (or dotted data paths) to export. Nothing else
+	// is exported.
+	Namespaces []string `yaml:"namespaces"`
+
+	// Pretty indents the JSON files (default compact)
+	Pretty bool `yaml:"pretty"`
+}
+
+// Default returns the configuration used when genny.yaml doesn't exist
+func Default() *Config {
+	return &Config{
+		Taxonomies:  make(map[string]Taxonomy),
+		Schemas:     make(map[string]string),
+		Collections: make(map[string]Collection),
+	}
+}
@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// APIDir is the output directory of exported data, relative to www/
+const APIDir = "api"
+
+// APIIndexFile lists the exported namespaces
+const APIIndexFile = "index.json"
+
+// APIOptions configures the JSON export of data namespaces
+type APIOptions struct {
+	Namespaces []string // Data namespaces (or dotted data paths) to export
+	Pretty     bool     // Indent the JSON instead of writing it compactly
+}
+
+// APIEntry describes one exported namespace in the API index
+type APIEntry struct {
+	Name string `json:"name"` // Namespace or data path, as allowlisted
+	Path string `json:"path"` // File name, relative to the index
+}
+
+// ExportAPI writes every allowlisted data namespace as JSON to www/api/<name>.json, with
+// an index.json listing them. Data read by an encrypted page is never exported: listing a
+// namespace that holds any of it fails the build instead, as does exporting anything while
+// an encrypted page uses its data in a way that can't be analyzed.
+func (g *MainSiteGenerator) ExportAPI(site *Site, options *APIOptions) error {
+	if options == nil || len(options.Namespaces) == 0 {
+		return nil
+	}
+
+	protected, err := encryptedNamespaces(site)
+	if err != nil {
+		return err
+	}
+	outputs := make(map[string]bool, len(site.Outputs))
+	for _, output := range site.Outputs {
+		outputs[filepath.ToSlash(output.OutputPath)] = true
+	}
+
+	apiDir := filepath.Join(g.outputDir, APIDir)
+	if err := os.MkdirAll(apiDir, 0755); err != nil {
+		return fmt.Errorf("failed to create API directory: %w", err)
+	}
+
+	index := make([]APIEntry, 0, len(options.Namespaces))
+	for _, name := range options.Namespaces {
+		name = strings.TrimPrefix(name, ".")
+		for i, namespace := range dataSources(site, name) {
+			page, ok := protected[namespace]
+			if !ok {
+				continue
+			}
+			if i == 0 {
+				return fmt.Errorf("refusing to export '%s': namespace '%s' is read by encrypted page %s", name, namespace, page)
+			}
+			return fmt.Errorf("refusing to export '%s': it holds data from namespace '%s', which is read by encrypted page %s", name, namespace, page)
+		}
+
+		value, err := site.Data.Get("." + name)
+		if err != nil {
+			return fmt.Errorf("failed to export '%s': %w", name, err)
+		}
+
+		entry := APIEntry{Name: name, Path: name + ".json"}
+		if outputs[APIDir+"/"+entry.Path] {
+			return fmt.Errorf("failed to export '%s': %s/%s is also generated by an output template", name, APIDir, entry.Path)
+		}
+		if err := writeJSON(filepath.Join(apiDir, entry.Path), value, options.Pretty); err != nil {
+			return fmt.Errorf("failed to export '%s': %w", name, err)
+		}
+		index = append(index, entry)
+	}
+
+	return writeJSON(filepath.Join(apiDir, APIIndexFile), index, options.Pretty)
+}
+
+// encryptedNamespaces returns the data namespaces that encrypted pages (published or held
+// back) read, mapped to one such page. A page reads a namespace when its collection or
+// pagination source is in it, when its template or a component it calls reads a data path
+// from the root data in it (see templateReads), or when it is the preview data path of
+// such a component. Reading a namespace reads the namespaces its data was copied from too
+// (see dataSources). A page that uses the root data in a way that can't be followed could
+// read any namespace, so it fails the export.
+func encryptedNamespaces(site *Site) (map[string]string, error) {
+	namespaces := make(map[string]string)
+	add := func(path string, page *Page) {
+		for _, namespace := range dataSources(site, path) {
+			if _, ok := namespaces[namespace]; !ok {
+				namespaces[namespace] = page.SourcePath
+			}
+		}
+	}
+
+	for _, page := range site.AllPages() {
+		if page.EncryptKey == "" {
+			continue
+		}
+
+		if collection, ok := page.Meta["collection"].(string); ok {
+			add(collection, page)
+		}
+		if paginate, ok := page.Meta["paginate"].(map[string]interface{}); ok {
+			if collection, ok := paginate["collection"].(string); ok {
+				add(collection, page)
+			}
+		}
+
+		reads, unknown, err := pageReads(site, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze encrypted page %s: %w", page.SourcePath, err)
+		}
+		if unknown != "" {
+			return nil, fmt.Errorf("refusing to export data: encrypted page %s uses %s, so the namespaces it reads can't be determined", page.SourcePath, unknown)
+		}
+		for _, path := range reads {
+			add(path, page)
+		}
+	}
+
+	return namespaces, nil
+}
+
+// dataSources returns the namespaces the data at a path comes from: the namespace the path
+// starts with first, then those its data was copied from by $ref or a derived collection
+// (Site.DataLinks), followed through further links. A path into the derived namespace is
+// linked by its collection (.collections.latest); the namespace as a whole by all of them.
+func dataSources(site *Site, path string) []string {
+	var namespaces []string
+	found := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	linked := make([]string, 0, len(site.DataLinks))
+	for key := range site.DataLinks {
+		linked = append(linked, key)
+	}
+	sort.Strings(linked)
+
+	var visit func(path string)
+	visit = func(path string) {
+		segments := strings.SplitN(strings.TrimPrefix(path, "."), ".", 3)
+		for i, segment := range segments {
+			segments[i], _, _ = strings.Cut(segment, "[")
+		}
+		namespace := segments[0]
+		if namespace == "" {
+			return
+		}
+		if !found[namespace] {
+			found[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+
+		key := namespace
+		if namespace == DerivedNamespace && len(segments) > 1 && segments[1] != "" {
+			key += "." + segments[1]
+		}
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+
+		for _, link := range linked {
+			if link == key || (key == DerivedNamespace && strings.HasPrefix(link, DerivedNamespace+".")) {
+				for _, source := range site.DataLinks[link] {
+					visit(source)
+				}
+			}
+		}
+	}
+
+	visit(path)
+	return namespaces
+}
+
+// writeJSON writes a value as JSON, indented when pretty
+func writeJSON(path string, value interface{}, pretty bool) error {
+	var content []byte
+	var err error
+	if pretty {
+		content, err = json.MarshalIndent(value, "", "  ")
+	} else {
+		content, err = json.Marshal(value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exportSite returns a site with one encrypted page, the given data links and data that
+// copies secret values into other namespaces the way $ref and derived collections do
+func exportSite(content string, links map[string][]string) *Site {
+	secret := map[string]interface{}{"items": []interface{}{map[string]interface{}{"name": "merger"}}}
+	acme := map[string]interface{}{"name": "Acme", "revenue": 42}
+	data := map[string]interface{}{
+		"secret":      secret,
+		"companies":   map[string]interface{}{"acme": acme},
+		"blog":        map[string]interface{}{"authors": map[string]interface{}{"alice": map[string]interface{}{"company": acme}}},
+		"collections": map[string]interface{}{"latest": secret["items"], "public": []interface{}{}},
+		"news":        map[string]interface{}{"title": "News"},
+	}
+
+	page := &Page{SourcePath: "priv.html", OutputPath: "priv.html", Content: content, EncryptKey: "pw"}
+	return &Site{
+		Data:       NewSimpleDataContext(data),
+		Components: map[string]*Component{},
+		Pages:      []*Page{page},
+		DataLinks:  links,
+	}
+}
+
+func TestExportAPIProtectsCopiedData(t *testing.T) {
+	links := map[string][]string{
+		"blog":               {"companies"},
+		"collections.latest": {"secret"},
+		"collections.public": {"news"},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		export  string
+		wantErr string
+	}{
+		{"collection source", `{{ range .collections.latest }}{{ .name }}{{ end }}`, "secret", "namespace 'secret' is read by encrypted page priv.html"},
+		{"collection by index", `{{ range index . "collections" }}{{ end }}`, "secret", "namespace 'secret' is read by encrypted page priv.html"},
+		{"other collection", `{{ range .collections.public }}{{ end }}`, "secret", ""},
+		{"exported collection holds read data", `{{ .secret.items }}`, "collections.latest", "holds data from namespace 'secret'"},
+		{"ref target", `{{ .blog.authors.alice.company.revenue }}`, "companies", "namespace 'companies' is read by encrypted page priv.html"},
+		{"exported namespace holds read ref target", `{{ .companies.acme.revenue }}`, "blog", "holds data from namespace 'companies'"},
+		{"unrelated namespace", `{{ .blog.authors.alice.company.revenue }}`, "news", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := exportSite(tt.content, links)
+			g := NewMainSiteGenerator(t.TempDir(), false)
+			err := g.ExportAPI(site, &APIOptions{Namespaces: []string{tt.export}})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ExportAPI(%s) error = %v", tt.export, err)
+				}
+				if _, err := os.Stat(filepath.Join(g.outputDir, APIDir, tt.export+".json")); err != nil {
+					t.Errorf("ExportAPI(%s) wrote no file: %v", tt.export, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ExportAPI(%s) error = %v, want %q", tt.export, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDataSources(t *testing.T) {
+	site := &Site{DataLinks: map[string][]string{
+		"blog":               {"companies"},
+		"companies":          {"people"},
+		"people":             {"blog"},
+		"collections.latest": {"secret"},
+		"collections.team":   {"people"},
+	}}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"news.title", []string{"news"}},
+		{".blog.authors", []string{"blog", "companies", "people"}},
+		{"collections.latest[0].name", []string{"collections", "secret"}},
+		{"collections", []string{"collections", "secret", "people", "blog", "companies"}},
+	}
+
+	for _, tt := range tests {
+		if got := dataSources(site, tt.path); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("dataSources(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
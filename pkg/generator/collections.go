@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// collectionKeyPattern matches a collection key placeholder in a page path (e.g. "[slug]")
+var collectionKeyPattern = regexp.MustCompile(`\[([A-Za-z0-9_-]+)\]`)
+
+// IsCollectionPath reports whether a page path contains a collection key placeholder
+func IsCollectionPath(path string) bool {
+	return collectionKeyPattern.MatchString(path)
+}
+
+// ExpandCollectionPages replaces every collection template page with one page per item
+// of the data list it is bound to. A collection template is a page whose path contains
+// a key placeholder (e.g. projects/[slug].html) and whose front matter declares the
+// data list to iterate:
+//
+//	---
+//	collection: .projects.items
+//	---
+//
+// Each generated page gets the item as its DataContext and an output path built by
+// substituting the placeholder with the item's value for that key.
+func ExpandCollectionPages(pages []*Page, data DataContext) ([]*Page, error) {
+	var result []*Page
+	seen := make(map[string]string)
+
+	for _, page := range pages {
+		collectionPath, _ := page.Meta["collection"].(string)
+		isTemplate := IsCollectionPath(page.OutputPath)
+
+		if collectionPath == "" && !isTemplate {
+			result = append(result, page)
+			continue
+		}
+		if collectionPath == "" {
+			return nil, &ValidationError{
+				Field:   page.SourcePath,
+				Message: "collection template has no 'collection' data path in its front matter",
+			}
+		}
+		if !isTemplate {
+			return nil, &ValidationError{
+				Field:   page.SourcePath,
+				Message: "page declares a collection but its path has no [key] placeholder",
+			}
+		}
+
+		expanded, err := expandCollectionPage(page, collectionPath, data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range expanded {
+			if source, exists := seen[p.OutputPath]; exists {
+				return nil, &ValidationError{
+					Field:   page.SourcePath,
+					Message: fmt.Sprintf("output path %s is also generated from %s", p.OutputPath, source),
+				}
+			}
+			seen[p.OutputPath] = page.SourcePath
+		}
+		result = append(result, expanded...)
+	}
+
+	return result, nil
+}
+
+// expandCollectionPage generates the pages for a single collection template
+func expandCollectionPage(page *Page, collectionPath string, data DataContext) ([]*Page, error) {
+	if !strings.HasPrefix(collectionPath, ".") {
+		collectionPath = "." + collectionPath
+	}
+
+	value, err := data.Get(collectionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection for page %s: %w", page.SourcePath, err)
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, &ValidationError{
+			Field:   page.SourcePath,
+			Message: fmt.Sprintf("collection %s is a %T, not a list", collectionPath, value),
+		}
+	}
+
+	var pages []*Page
+	for i, item := range items {
+		outputPath, err := collectionOutputPath(page.OutputPath, item)
+		if err != nil {
+			return nil, &ValidationError{
+				Field:   fmt.Sprintf("%s (item %d of %s)", page.SourcePath, i, collectionPath),
+				Message: err.Error(),
+			}
+		}
+
+		generated := *page
+		generated.OutputPath = outputPath
+		generated.DataContext = item
+		pages = append(pages, &generated)
+	}
+
+	return pages, nil
+}
+
+// collectionOutputPath substitutes every [key] placeholder in the template path
+// with the item's value for that key
+func collectionOutputPath(templatePath string, item interface{}) (string, error) {
+	fields, ok := item.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("item is a %T, not a map", item)
+	}
+
+	var substErr error
+	outputPath := collectionKeyPattern.ReplaceAllStringFunc(templatePath, func(match string) string {
+		key := collectionKeyPattern.FindStringSubmatch(match)[1]
+		value, exists := fields[key]
+		if !exists {
+			substErr = fmt.Errorf("item has no '%s' key", key)
+			return match
+		}
+
+		segment := strings.TrimSpace(fmt.Sprint(value))
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `/\`) {
+			substErr = fmt.Errorf("item key '%s' has invalid path value '%v'", key, value)
+			return match
+		}
+		return segment
+	})
+	if substErr != nil {
+		return "", substErr
+	}
+
+	return filepath.Clean(outputPath), nil
+}
@@ -13,11 +13,13 @@ import (
 type ComponentGenerator struct {
 	outputDir string
 	verbose   bool
+	strict    bool
 }
 
-// NewComponentGenerator creates a new ComponentGenerator
-func NewComponentGenerator(outputDir string, verbose bool) *ComponentGenerator {
-	return &ComponentGenerator{outputDir: outputDir, verbose: verbose}
+// NewComponentGenerator creates a new ComponentGenerator. In strict mode, components that
+// read missing data fail the build.
+func NewComponentGenerator(outputDir string, verbose, strict bool) *ComponentGenerator {
+	return &ComponentGenerator{outputDir: outputDir, verbose: verbose, strict: strict}
 }
 
 // GenerateComponentPreviews generates preview pages for all components
@@ -28,7 +30,7 @@ func (g *ComponentGenerator) GenerateComponentPreviews(site *Site, wrapperTempla
 	}
 
 	// Create a template set with all components
-	t := template.New("components")
+	t := NewTemplate("components", g.strict).Funcs(PageFuncs(site, nil, true))
 	for name, comp := range site.Components {
 		_, err := t.New(name).Parse(comp.Template)
 		if err != nil {
@@ -42,7 +44,7 @@ func (g *ComponentGenerator) GenerateComponentPreviews(site *Site, wrapperTempla
 
 	// Generate preview for each component
 	for name, comp := range site.Components {
-		if err := g.generateComponentPreview(name, comp, t, wrapperTemplate, site.Data); err != nil {
+		if err := g.generateComponentPreview(name, comp, t, wrapperTemplate, site); err != nil {
 			return fmt.Errorf("failed to generate preview for component %s: %w", name, err)
 		}
 	}
@@ -51,13 +53,13 @@ func (g *ComponentGenerator) GenerateComponentPreviews(site *Site, wrapperTempla
 }
 
 // generateComponentPreview generates a single component preview
-func (g *ComponentGenerator) generateComponentPreview(name string, comp *Component, templateSet *template.Template, wrapperTemplate *template.Template, dataContext DataContext) error {
+func (g *ComponentGenerator) generateComponentPreview(name string, comp *Component, templateSet *template.Template, wrapperTemplate *template.Template, site *Site) error {
 	if g.verbose {
 		fmt.Printf("DEBUG: Component %s has DataPath: '%s'\n", name, comp.DataPath)
 	}
 
 	// Get the data for this component
-	data, err := dataContext.Get(comp.DataPath)
+	data, err := site.Data.Get(comp.DataPath)
 	if err != nil {
 		return fmt.Errorf("failed to get data for component %s at path %s: %w", name, comp.DataPath, err)
 	}
@@ -74,10 +76,7 @@ func (g *ComponentGenerator) generateComponentPreview(name string, comp *Compone
 	}
 
 	if err := componentTmpl.Execute(&componentBuf, data); err != nil {
-		return &TemplateExecuteError{
-			Name: name,
-			Err:  err,
-		}
+		return executeError("preview/"+name+".html", err)
 	}
 
 	// Wrap the component in the wrapper template
@@ -89,8 +88,14 @@ func (g *ComponentGenerator) generateComponentPreview(name string, comp *Compone
 		}
 	}
 
+	// Highlight code blocks
+	result, err := HighlightCode(resultBuf.String(), site.Highlight)
+	if err != nil {
+		return err
+	}
+
 	// Adjust paths for preview directory
-	result := AdjustPathsForPreview(resultBuf.String())
+	result = AdjustPathsForPreview(result)
 
 	// Clean up excessive whitespace
 	result = utils.CleanupWhitespace(result)
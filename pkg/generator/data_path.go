@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a data path: a map key or list index ("items", "0", "-1"),
+// or a list selector ("[name=alice]", "[2]")
+type pathSegment struct {
+	Text     string // The segment as written, for error messages
+	Key      string // Map key, or list index when it parses as an integer
+	Selector bool   // Written in brackets
+	Field    string // Selector field to match (for [field=value])
+	Value    string // Selector value to match
+}
+
+// LookupPath follows a data path from a value, using the same path language as
+// SimpleDataContext.Get
+func LookupPath(value interface{}, path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := value
+	for _, segment := range segments {
+		current, err = resolveSegment(path, current, segment)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return current, nil
+}
+
+// parsePath splits a data path into segments. Segments are separated by dots, and any
+// segment may be followed by bracketed selectors: ".team[name=alice].roles[0]".
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	rest := path
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, &DataPathError{Path: path, Part: rest, Reason: "unclosed '['"}
+			}
+			segment, err := parseSelector(path, rest[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, pathSegment{Text: rest[:end], Key: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+
+	return segments, nil
+}
+
+// parseSelector parses a bracketed selector: an index ("[0]", "[-1]") or a key=value match
+func parseSelector(path, text string) (pathSegment, error) {
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	segment := pathSegment{Text: text, Selector: true}
+
+	field, value, isMatch := strings.Cut(inner, "=")
+	if !isMatch {
+		if _, err := strconv.Atoi(inner); err != nil {
+			return segment, &DataPathError{Path: path, Part: text, Reason: "a selector must be an index like [0] or a match like [name=alice]"}
+		}
+		segment.Key = inner
+		return segment, nil
+	}
+
+	segment.Field = strings.TrimSpace(field)
+	segment.Value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if segment.Field == "" {
+		return segment, &DataPathError{Path: path, Part: text, Reason: "selector has no field name"}
+	}
+	return segment, nil
+}
+
+// resolveSegment steps from a value into one of its children
+func resolveSegment(path string, current interface{}, segment pathSegment) (interface{}, error) {
+	switch value := current.(type) {
+	case map[string]interface{}:
+		if segment.Selector {
+			return nil, &DataPathError{Path: path, Part: segment.Text, Found: describeValue(current), Reason: "selectors only apply to lists"}
+		}
+		child, ok := value[segment.Key]
+		if !ok {
+			return nil, &DataPathError{Path: path, Part: segment.Text, Found: describeValue(current), Reason: fmt.Sprintf("no key '%s' (keys: %s)", segment.Key, mapKeys(value))}
+		}
+		return child, nil
+
+	case []interface{}:
+		index, err := listIndex(path, value, segment)
+		if err != nil {
+			return nil, err
+		}
+		return value[index], nil
+
+	default:
+		return nil, &DataPathError{Path: path, Part: segment.Text, Found: describeValue(current), Reason: "only maps and lists can be walked into"}
+	}
+}
+
+// listIndex finds the list element a segment refers to: a position (negative counts from
+// the end) or the first map element whose field matches a selector
+func listIndex(path string, list []interface{}, segment pathSegment) (int, error) {
+	if segment.Field != "" {
+		for i, item := range list {
+			if m, ok := item.(map[string]interface{}); ok {
+				if field, ok := m[segment.Field]; ok && fmt.Sprint(field) == segment.Value {
+					return i, nil
+				}
+			}
+		}
+		return 0, &DataPathError{Path: path, Part: segment.Text, Found: describeValue(list), Reason: fmt.Sprintf("no item has %s=%s", segment.Field, segment.Value)}
+	}
+
+	index, err := strconv.Atoi(segment.Key)
+	if err != nil {
+		return 0, &DataPathError{Path: path, Part: segment.Text, Found: describeValue(list), Reason: "expected an index or a [field=value] selector"}
+	}
+	if index < 0 {
+		index += len(list)
+	}
+	if index < 0 || index >= len(list) {
+		return 0, &DataPathError{Path: path, Part: segment.Text, Found: describeValue(list), Reason: "index out of range"}
+	}
+	return index, nil
+}
+
+// describeValue names a data value's type for error messages
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return fmt.Sprintf("map with %d keys", len(v))
+	case []interface{}:
+		return fmt.Sprintf("list of %d items", len(v))
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, int64, float64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// mapKeys lists a map's keys in order for error messages
+func mapKeys(m map[string]interface{}) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
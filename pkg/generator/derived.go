@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DerivedNamespace is the data namespace holding derived collections (.collections.latest)
+const DerivedNamespace = "collections"
+
+// CollectionQuery describes a collection derived from a list in the loaded data. Steps are
+// applied in order: filter, sort, reverse, limit, then group.
+type CollectionQuery struct {
+	From    string                 // Data path of the source list
+	Filter  map[string]interface{} // Field → value every kept item must have (list fields must contain it)
+	Sort    string                 // Field to sort by, ascending
+	Reverse bool                   // Reverse the order after sorting
+	Limit   int                    // Keep at most this many items (0 keeps all)
+	GroupBy string                 // Field to group by; the result is a list of {key, items} groups
+}
+
+// DeriveCollection computes a derived collection from the loaded data
+func DeriveCollection(data DataContext, query CollectionQuery) ([]interface{}, error) {
+	from := query.From
+	if !strings.HasPrefix(from, ".") {
+		from = "." + from
+	}
+
+	value, err := data.Get(from)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("source %s is a %s, not a list", from, describeValue(value))
+	}
+
+	var items []interface{}
+	for _, item := range source {
+		if matchesFilter(item, query.Filter) {
+			items = append(items, item)
+		}
+	}
+
+	if query.Sort != "" {
+		sort.SliceStable(items, func(i, j int) bool {
+			return compareValues(fieldValue(items[i], query.Sort), fieldValue(items[j], query.Sort)) < 0
+		})
+	}
+
+	if query.Reverse {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	if query.Limit > 0 && len(items) > query.Limit {
+		items = items[:query.Limit]
+	}
+
+	if query.GroupBy != "" {
+		return groupItems(items, query.GroupBy), nil
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	return items, nil
+}
+
+// matchesFilter reports whether an item has every filtered field value. A list field
+// matches when it contains the value.
+func matchesFilter(item interface{}, filter map[string]interface{}) bool {
+	for field, want := range filter {
+		value := fieldValue(item, field)
+		if list, ok := value.([]interface{}); ok {
+			found := false
+			for _, element := range list {
+				if fmt.Sprint(element) == fmt.Sprint(want) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+			continue
+		}
+		if value == nil || fmt.Sprint(value) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupItems groups items by a field's value, keeping groups in order of first appearance.
+// Items without the field are left out.
+func groupItems(items []interface{}, field string) []interface{} {
+	groups := []interface{}{}
+	index := make(map[string]map[string]interface{})
+
+	for _, item := range items {
+		value := fieldValue(item, field)
+		if value == nil {
+			continue
+		}
+		key := fmt.Sprint(value)
+		group, ok := index[key]
+		if !ok {
+			group = map[string]interface{}{"key": value, "items": []interface{}{}}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group["items"] = append(group["items"].([]interface{}), item)
+	}
+
+	return groups
+}
+
+// fieldValue reads a (possibly dotted) field from an item, or nil if it doesn't have it
+func fieldValue(item interface{}, field string) interface{} {
+	value, err := LookupPath(item, field)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// compareValues orders two data values: numbers numerically, dates chronologically,
+// anything else as text. Missing values sort last.
+func compareValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// toFloat converts the numeric types produced by the data decoders to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
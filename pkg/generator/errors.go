@@ -1,6 +1,9 @@
 package generator
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ComponentNotFoundError indicates a component was referenced but doesn't exist
 type ComponentNotFoundError struct {
@@ -13,12 +16,21 @@ func (e *ComponentNotFoundError) Error() string {
 
 // DataPathError indicates an invalid or unreachable data path
 type DataPathError struct {
-	Path string
-	Part string
+	Path   string
+	Part   string // The segment that failed
+	Found  string // The type of value the segment was applied to (e.g. "list of 3 items")
+	Reason string
 }
 
 func (e *DataPathError) Error() string {
-	return fmt.Sprintf("invalid data path '%s' at part '%s'", e.Path, e.Part)
+	msg := fmt.Sprintf("invalid data path '%s' at part '%s'", e.Path, e.Part)
+	if e.Found != "" {
+		msg += fmt.Sprintf(" (found %s)", e.Found)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
 }
 
 // TemplateParseError indicates a template could not be parsed
@@ -50,6 +62,32 @@ func (e *TemplateExecuteError) Unwrap() error {
 	return e.Err
 }
 
+// MissingDataError indicates a template read data that doesn't exist while strict mode was on
+type MissingDataError struct {
+	Page      string // Output path of the page being generated
+	Template  string // Template that read the data
+	Component string // Component that read the data, empty for the page's own templates
+	Path      string // Data path as written in the template (e.g. .Item.tittle)
+	Err       error
+}
+
+func (e *MissingDataError) Error() string {
+	var msg string
+	if e.Component != "" {
+		msg = fmt.Sprintf("page '%s': component '%s' reads missing data '%s'", e.Page, e.Component, e.Path)
+	} else {
+		msg = fmt.Sprintf("page '%s': template '%s' reads missing data '%s'", e.Page, e.Template, e.Path)
+	}
+	if strings.HasPrefix(e.Path, ".Env.") {
+		msg += " (only environment variables listed under env in genny.yaml are exposed)"
+	}
+	return msg
+}
+
+func (e *MissingDataError) Unwrap() error {
+	return e.Err
+}
+
 // FileNotFoundError indicates a required file was not found
 type FileNotFoundError struct {
 	Path string
@@ -68,3 +106,32 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error in %s: %s", e.Field, e.Message)
 }
+
+// SchemaViolation is a single place where a data file doesn't match its schema
+type SchemaViolation struct {
+	File    string
+	Line    int // 0 if the position is unknown
+	Column  int
+	Path    string // JSON pointer to the offending value (e.g. /items/0/title)
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s: %s", v.File, v.Line, v.Column, v.Path, v.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", v.File, v.Path, v.Message)
+}
+
+// DataSchemaError indicates data files that don't match their JSON Schemas
+type DataSchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *DataSchemaError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = "  " + v.String()
+	}
+	return fmt.Sprintf("%d data schema violation(s):\n%s", len(e.Violations), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,327 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"genny/pkg/utils"
+
+	"github.com/yuin/goldmark"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// templateFuncs returns the helper functions registered on every template set. Functions
+// that take the value being transformed take it last, so they work in pipelines:
+// {{ .title | truncate 40 }}.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"slugify":  utils.Slugify,
+		"truncate": truncate,
+		"title":    titleCase,
+		"replace":  replace,
+
+		// Dates
+		"date":      formatDate,
+		"parseDate": parseDate,
+		"now":       time.Now,
+
+		// Math
+		"add": func(a, b interface{}) (interface{}, error) { return arithmetic("add", a, b) },
+		"sub": func(a, b interface{}) (interface{}, error) { return arithmetic("sub", a, b) },
+		"mul": func(a, b interface{}) (interface{}, error) { return arithmetic("mul", a, b) },
+		"div": func(a, b interface{}) (interface{}, error) { return arithmetic("div", a, b) },
+		"mod": func(a, b interface{}) (interface{}, error) { return arithmetic("mod", a, b) },
+
+		// Values
+		"dict":    dict,
+		"list":    list,
+		"default": defaultValue,
+		"jsonify": jsonify,
+
+		// HTML
+		"markdownify": markdownify,
+		"safeHTML":    func(s string) template.HTML { return template.HTML(s) },
+		"safeURL":     func(s string) template.URL { return template.URL(s) },
+
+		// Lists
+		"where":  where,
+		"sortBy": sortBy,
+	}
+}
+
+// truncate shortens text to at most length characters, ending it with an ellipsis
+// when anything was cut: {{ .summary | truncate 120 }}
+func truncate(length int, text string) string {
+	if length < 0 || utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	if length == 0 {
+		return ""
+	}
+	runes := []rune(text)
+	return strings.TrimRightFunc(string(runes[:length-1]), func(r rune) bool { return r == ' ' }) + "…"
+}
+
+// titleCase capitalizes each word: {{ "hello world" | title }} gives "Hello World"
+func titleCase(text string) string {
+	return cases.Title(language.Und, cases.NoLower).String(text)
+}
+
+// replace replaces every occurrence of old: {{ .name | replace "-" " " }}
+func replace(old, new, text string) string {
+	return strings.ReplaceAll(text, old, new)
+}
+
+// formatDate formats a time, or a date string in any front matter date format, with a Go
+// layout: {{ .Page.History.Updated | date "2 Jan 2006" }}
+func formatDate(layout string, value interface{}) (string, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// parseDate parses a date string in any front matter date format (RFC 3339, "2006-01-02",
+// "2006-01-02 15:04"...) into a time
+func parseDate(value string) (time.Time, error) {
+	return toTime(value)
+}
+
+// toTime converts a data value to a time
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v != nil {
+			return *v, nil
+		}
+	case string:
+		v = strings.TrimSpace(v)
+		for _, layout := range dateLayouts {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid date '%s'", v)
+	}
+	return time.Time{}, fmt.Errorf("invalid date '%v' (%T)", value, value)
+}
+
+// arithmetic applies a math operation. Two integers give an integer; anything else is
+// computed as floats. Division by zero is an error.
+func arithmetic(op string, a, b interface{}) (interface{}, error) {
+	x, xOK := toNumber(a)
+	y, yOK := toNumber(b)
+	if !xOK || !yOK {
+		return nil, fmt.Errorf("%s: expected numbers, got %T and %T", op, a, b)
+	}
+
+	xi, xInt := x.(int64)
+	yi, yInt := y.(int64)
+	if xInt && yInt {
+		switch op {
+		case "add":
+			return xi + yi, nil
+		case "sub":
+			return xi - yi, nil
+		case "mul":
+			return xi * yi, nil
+		case "div", "mod":
+			if yi == 0 {
+				return nil, fmt.Errorf("%s: division by zero", op)
+			}
+			if op == "mod" {
+				return xi % yi, nil
+			}
+			return xi / yi, nil
+		}
+	}
+
+	xf, yf := toFloat64(x), toFloat64(y)
+	switch op {
+	case "add":
+		return xf + yf, nil
+	case "sub":
+		return xf - yf, nil
+	case "mul":
+		return xf * yf, nil
+	case "div", "mod":
+		if yf == 0 {
+			return nil, fmt.Errorf("%s: division by zero", op)
+		}
+		if op == "mod" {
+			return math.Mod(xf, yf), nil
+		}
+		return xf / yf, nil
+	}
+	return nil, fmt.Errorf("unknown operation %s", op)
+}
+
+// toNumber converts any Go number to int64 or float64
+func toNumber(value interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return nil, false
+}
+
+// toFloat64 widens a number from toNumber to float64
+func toFloat64(number interface{}) float64 {
+	if i, ok := number.(int64); ok {
+		return float64(i)
+	}
+	return number.(float64)
+}
+
+// dict builds a map from key/value pairs, for passing several values to a component:
+// {{ template "card" dict "title" .name "url" .link }}
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: expected key/value pairs, got %d arguments", len(pairs))
+	}
+	result := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is a %T, not a string", pairs[i], pairs[i])
+		}
+		result[key] = pairs[i+1]
+	}
+	return result, nil
+}
+
+// list builds a list from its arguments: {{ range list "a" "b" "c" }}
+func list(values ...interface{}) []interface{} {
+	return append([]interface{}{}, values...)
+}
+
+// defaultValue returns value, or fallback when value is empty (nil, false, zero, "" or
+// an empty list or map): {{ .subtitle | default "Untitled" }}. In strict mode reading a
+// missing key fails before default is called, so optional keys are read with index,
+// which gives nil for them: {{ index . "subtitle" | default "Untitled" }}
+func defaultValue(fallback, value interface{}) interface{} {
+	if isEmpty(value) {
+		return fallback
+	}
+	return value
+}
+
+// isEmpty reports whether a value counts as empty for default
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// jsonify encodes a value as JSON, written as-is inside <script> and escaped elsewhere:
+// <script>const projects = {{ jsonify .projects.items }};</script>
+func jsonify(value interface{}) (template.JS, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("jsonify: %w", err)
+	}
+	return template.JS(encoded), nil
+}
+
+// markdownify renders Markdown to HTML: {{ .description | markdownify }}
+func markdownify(text string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(text), &buf); err != nil {
+		return "", fmt.Errorf("markdownify: %w", err)
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// where keeps the items of a list whose field equals value (or, for list fields,
+// contains it): {{ range where .projects.items "status" "active" }}
+func where(items interface{}, field string, value interface{}) ([]interface{}, error) {
+	source, err := toList("where", items)
+	if err != nil {
+		return nil, err
+	}
+	result := []interface{}{}
+	for _, item := range source {
+		if matchesFilter(item, map[string]interface{}{field: value}) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// sortBy returns a copy of a list sorted by a field, ascending unless "desc" is given:
+// {{ range sortBy .projects.items "date" "desc" }}
+func sortBy(items interface{}, field string, order ...string) ([]interface{}, error) {
+	source, err := toList("sortBy", items)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) > 1 {
+		return nil, fmt.Errorf("sortBy: expected at most one order, got %d", len(order))
+	}
+	descending := false
+	if len(order) == 1 {
+		switch strings.ToLower(order[0]) {
+		case "asc":
+		case "desc":
+			descending = true
+		default:
+			return nil, fmt.Errorf("sortBy: order must be \"asc\" or \"desc\", got %q", order[0])
+		}
+	}
+
+	result := append([]interface{}{}, source...)
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := fieldValue(result[i], field), fieldValue(result[j], field)
+		if descending {
+			// Missing values still sort last
+			if a == nil || b == nil {
+				return compareValues(a, b) < 0
+			}
+			return compareValues(b, a) < 0
+		}
+		return compareValues(a, b) < 0
+	})
+	return result, nil
+}
+
+// toList converts any slice to []interface{} for the list helpers
+func toList(name string, items interface{}) ([]interface{}, error) {
+	if list, ok := items.([]interface{}); ok {
+		return list, nil
+	}
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s: expected a list, got %T", name, items)
+	}
+	result := make([]interface{}, v.Len())
+	for i := range result {
+		result[i] = v.Index(i).Interface()
+	}
+	return result, nil
+}
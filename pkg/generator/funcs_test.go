@@ -0,0 +1,424 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"genny/pkg/utils"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		text   string
+		want   string
+	}{
+		{"shorter", 10, "hello", "hello"},
+		{"exact", 5, "hello", "hello"},
+		{"cut", 8, "hello world", "hello w…"},
+		{"trailing space trimmed", 7, "hello world", "hello…"},
+		{"runes", 3, "héllo", "hé…"},
+		{"zero", 0, "hello", ""},
+		{"negative", -1, "hello", "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.length, tt.text); got != tt.want {
+				t.Errorf("truncate(%d, %q) = %q, want %q", tt.length, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"words", "Hello World", "hello-world"},
+		{"empty", "", ""},
+		{"only punctuation", "?!", ""},
+		{"leading and trailing punctuation", "  --Hello, World!--  ", "hello-world"},
+		{"repeated separators", "a  _ b", "a-b"},
+		{"digits", "Go 1.22", "go-1-22"},
+		{"non-ascii letters", "Ünïcödé Straße", "ünïcödé-straße"},
+		{"non-latin", "Привет мир", "привет-мир"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := utils.Slugify(tt.text); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"hello world", "Hello World"},
+		{"already Title", "Already Title"},
+		{"keep ACRONYMS", "Keep ACRONYMS"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := titleCase(tt.text); got != tt.want {
+			t.Errorf("titleCase(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		old, new, text string
+		want           string
+	}{
+		{"-", " ", "a-b-c", "a b c"},
+		{"x", "y", "abc", "abc"},
+		{"ab", "", "abab", ""},
+	}
+
+	for _, tt := range tests {
+		if got := replace(tt.old, tt.new, tt.text); got != tt.want {
+			t.Errorf("replace(%q, %q, %q) = %q, want %q", tt.old, tt.new, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	local := time.Date(2024, 3, 5, 14, 30, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    time.Time
+		wantErr bool
+	}{
+		{"time", local, local, false},
+		{"time pointer", &local, local, false},
+		{"date", "2024-03-05", time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local), false},
+		{"date and minutes", "2024-03-05 14:30", local, false},
+		{"rfc3339", "2024-03-05T14:30:00Z", time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC), false},
+		{"padded", "  2024-03-05  ", time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local), false},
+		{"bad string", "yesterday", time.Time{}, true},
+		{"nil pointer", (*time.Time)(nil), time.Time{}, true},
+		{"number", 42, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toTime(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toTime(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("toTime(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	if got, err := formatDate("2 Jan 2006", "2024-03-05"); err != nil || got != "5 Mar 2024" {
+		t.Errorf("formatDate() = %q, %v, want \"5 Mar 2024\"", got, err)
+	}
+	if _, err := formatDate("2006", "someday"); err == nil {
+		t.Error("formatDate() with a bad date should fail")
+	}
+	if got, err := parseDate("2024-03-05 14:30"); err != nil || !got.Equal(local) {
+		t.Errorf("parseDate() = %v, %v, want %v", got, err, local)
+	}
+}
+
+func TestNow(t *testing.T) {
+	before := time.Now()
+	got := templateFuncs()["now"].(func() time.Time)()
+	if got.Before(before) || got.After(time.Now()) {
+		t.Errorf("now() = %v, want the current time", got)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		op      string
+		a, b    interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{"add", 1, 2, int64(3), ""},
+		{"sub", 1, 2, int64(-1), ""},
+		{"mul", 3, 4, int64(12), ""},
+		{"div", 7, 2, int64(3), ""},
+		{"mod", 7, 2, int64(1), ""},
+		{"add", uint8(1), int32(2), int64(3), ""},
+		{"add", 1, 0.5, 1.5, ""},
+		{"div", 7, 2.0, 3.5, ""},
+		{"mul", 1.5, 2, 3.0, ""},
+		{"mod", 7.5, 2, 1.5, ""},
+		{"div", 1, 0, nil, "division by zero"},
+		{"mod", 1, 0, nil, "division by zero"},
+		{"div", 1.5, 0.0, nil, "division by zero"},
+		{"add", "1", 2, nil, "expected numbers"},
+		{"pow", 1, 2, nil, "unknown operation"},
+	}
+
+	for _, tt := range tests {
+		got, err := arithmetic(tt.op, tt.a, tt.b)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s(%v, %v) error = %v, want %q", tt.op, tt.a, tt.b, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s(%v, %v) = %v (%T), %v, want %v (%T)", tt.op, tt.a, tt.b, got, got, err, tt.want, tt.want)
+		}
+	}
+}
+
+func TestDictAndList(t *testing.T) {
+	got, err := dict("title", "Home", "count", 2)
+	if err != nil {
+		t.Fatalf("dict() error = %v", err)
+	}
+	if want := map[string]interface{}{"title": "Home", "count": 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dict() = %v, want %v", got, want)
+	}
+
+	if got, err := dict(); err != nil || len(got) != 0 {
+		t.Errorf("dict() = %v, %v, want an empty map", got, err)
+	}
+	if _, err := dict("title", "Home", "count"); err == nil || !strings.Contains(err.Error(), "key/value pairs") {
+		t.Errorf("dict() with odd arguments error = %v", err)
+	}
+	if _, err := dict(1, "one"); err == nil || !strings.Contains(err.Error(), "not a string") {
+		t.Errorf("dict() with a non-string key error = %v", err)
+	}
+
+	if got := list("a", 1, nil); !reflect.DeepEqual(got, []interface{}{"a", 1, nil}) {
+		t.Errorf("list() = %v", got)
+	}
+	if got := list(); got == nil || len(got) != 0 {
+		t.Errorf("list() = %#v, want an empty list", got)
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"nil", nil, "fallback"},
+		{"empty string", "", "fallback"},
+		{"zero", 0, "fallback"},
+		{"false", false, "fallback"},
+		{"empty list", []interface{}{}, "fallback"},
+		{"empty map", map[string]interface{}{}, "fallback"},
+		{"nil pointer", (*Page)(nil), "fallback"},
+		{"string", "set", "set"},
+		{"number", 3, 3},
+		{"true", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultValue("fallback", tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("defaultValue(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultInStrictMode(t *testing.T) {
+	data := map[string]interface{}{"title": "Home"}
+
+	tmpl := NewTemplate("index", true)
+	if _, err := tmpl.Parse(`{{ index . "subtitle" | default "Untitled" }}`); err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil || buf.String() != "Untitled" {
+		t.Errorf("index | default = %q, %v, want \"Untitled\"", buf.String(), err)
+	}
+
+	tmpl = NewTemplate("field", true)
+	if _, err := tmpl.Parse(`{{ .subtitle | default "Untitled" }}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, data); err == nil {
+		t.Error("a missing field should fail before default in strict mode")
+	}
+}
+
+func TestJsonify(t *testing.T) {
+	got, err := jsonify(map[string]interface{}{"b": []int{1, 2}, "a": "<x>"})
+	if err != nil {
+		t.Fatalf("jsonify() error = %v", err)
+	}
+	if want := `{"a":"\u003cx\u003e","b":[1,2]}`; string(got) != want {
+		t.Errorf("jsonify() = %s, want %s", got, want)
+	}
+
+	if _, err := jsonify(func() {}); err == nil {
+		t.Error("jsonify() of a function should fail")
+	}
+}
+
+func TestMarkdownify(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"**bold**", "<p><strong>bold</strong></p>\n"},
+		{"# Title", "<h1>Title</h1>\n"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := markdownify(tt.text)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("markdownify(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestSafeHTMLAndURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		value    string
+		want     string
+	}{
+		{"escaped html", `{{ . }}`, "<b>x</b>", "&lt;b&gt;x&lt;/b&gt;"},
+		{"safeHTML", `{{ safeHTML . }}`, "<b>x</b>", "<b>x</b>"},
+		{"filtered url", `<a href="{{ . }}">`, "javascript:void", `<a href="#ZgotmplZ">`},
+		{"safeURL", `<a href="{{ safeURL . }}">`, "javascript:void", `<a href="javascript:void">`},
+		{"safeURL still escapes html", `<a href="{{ safeURL . }}">`, `/a"b`, `<a href="/a%22b">`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := NewTemplate(tt.name, true).Parse(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf strings.Builder
+			if err := tmpl.Execute(&buf, tt.value); err != nil || buf.String() != tt.want {
+				t.Errorf("%s = %q, %v, want %q", tt.template, buf.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhere(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"name": "a", "status": "active", "tags": []interface{}{"go"}},
+		map[string]interface{}{"name": "b", "status": "archived", "tags": []interface{}{"web"}},
+		map[string]interface{}{"name": "c", "status": "active", "tags": []interface{}{"go", "web"}},
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		value interface{}
+		want  []string
+	}{
+		{"field equals", "status", "active", []string{"a", "c"}},
+		{"list contains", "tags", "web", []string{"b", "c"}},
+		{"no match", "status", "draft", nil},
+		{"missing field", "owner", "x", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := where(items, tt.field, tt.value)
+			if err != nil {
+				t.Fatalf("where() error = %v", err)
+			}
+			if names := itemNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("where(%s, %v) = %v, want %v", tt.field, tt.value, names, tt.want)
+			}
+		})
+	}
+
+	typed := []map[string]interface{}{{"name": "a", "status": "active"}}
+	if got, err := where(typed, "status", "active"); err != nil || len(got) != 1 {
+		t.Errorf("where() on a typed slice = %v, %v", got, err)
+	}
+	if _, err := where("not a list", "status", "active"); err == nil || !strings.Contains(err.Error(), "expected a list") {
+		t.Errorf("where() on a string error = %v", err)
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	items := []interface{}{
+		map[string]interface{}{"name": "b", "rank": 2},
+		map[string]interface{}{"name": "none"},
+		map[string]interface{}{"name": "a", "rank": 10},
+		map[string]interface{}{"name": "c", "rank": 1},
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		order []string
+		want  []string
+	}{
+		{"ascending", "rank", nil, []string{"c", "b", "a", "none"}},
+		{"explicit ascending", "rank", []string{"asc"}, []string{"c", "b", "a", "none"}},
+		{"descending", "rank", []string{"desc"}, []string{"a", "b", "c", "none"}},
+		{"order is case-insensitive", "rank", []string{"DESC"}, []string{"a", "b", "c", "none"}},
+		{"strings", "name", nil, []string{"a", "b", "c", "none"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortBy(items, tt.field, tt.order...)
+			if err != nil {
+				t.Fatalf("sortBy() error = %v", err)
+			}
+			if names := itemNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("sortBy(%s, %v) = %v, want %v", tt.field, tt.order, names, tt.want)
+			}
+		})
+	}
+
+	if names := itemNames(items); names[0] != "b" {
+		t.Errorf("sortBy() changed its input: %v", names)
+	}
+
+	errorTests := []struct {
+		name    string
+		items   interface{}
+		order   []string
+		wantErr string
+	}{
+		{"not a list", map[string]interface{}{"a": 1}, nil, "expected a list"},
+		{"bad order", items, []string{"descending"}, `order must be "asc" or "desc"`},
+		{"two orders", items, []string{"asc", "desc"}, "at most one order"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sortBy(tt.items, "rank", tt.order...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("sortBy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// itemNames returns the name field of each item, for comparing list results
+func itemNames(items []interface{}) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// HighlightStylesheet is the stylesheet written next to the copied CSS files with the
+// colors of the highlighting theme
+const HighlightStylesheet = "highlight.css"
+
+// DefaultHighlightTheme is the theme used when highlighting is enabled without one
+const DefaultHighlightTheme = "github"
+
+// HighlightOptions configures build-time syntax highlighting
+type HighlightOptions struct {
+	Theme       string // Chroma style name (e.g. "github", "monokai", "dracula")
+	LineNumbers bool   // Number the lines of every highlighted block
+}
+
+// codeBlockPattern matches a <pre><code> block whose code element has a language-x class,
+// as written by hand or rendered from fenced Markdown code
+var codeBlockPattern = regexp.MustCompile(`(?s)<pre[^>]*>\s*<code[^>]*\bclass="(?:[^"]*\s)?language-([\w+#.-]+)[^"]*"[^>]*>(.*?)</code>\s*</pre>`)
+
+// CheckHighlightTheme returns an error naming the available themes if a theme doesn't exist
+func CheckHighlightTheme(theme string) error {
+	if _, ok := styles.Registry[strings.ToLower(theme)]; !ok {
+		return fmt.Errorf("unknown highlight theme '%s' (themes: %s)", theme, strings.Join(styles.Names(), ", "))
+	}
+	return nil
+}
+
+// HighlightCode replaces the language-tagged code blocks in rendered HTML with highlighted
+// HTML that uses CSS classes from the theme stylesheet. Blocks in a language chroma doesn't
+// know are left as they are. Without options, the HTML is returned unchanged.
+func HighlightCode(content string, options *HighlightOptions) (string, error) {
+	if options == nil || !strings.Contains(content, "language-") {
+		return content, nil
+	}
+
+	formatter := newCodeFormatter(options)
+	var highlightErr error
+	result := codeBlockPattern.ReplaceAllStringFunc(content, func(block string) string {
+		match := codeBlockPattern.FindStringSubmatch(block)
+		lexer := lexers.Get(match[1])
+		if lexer == nil || highlightErr != nil {
+			return block
+		}
+
+		code := html.UnescapeString(match[2])
+		iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+		if err != nil {
+			highlightErr = fmt.Errorf("failed to highlight %s code: %w", match[1], err)
+			return block
+		}
+
+		var buf bytes.Buffer
+		if err := formatter.Format(&buf, styles.Get(options.Theme), iterator); err != nil {
+			highlightErr = fmt.Errorf("failed to highlight %s code: %w", match[1], err)
+			return block
+		}
+		return buf.String()
+	})
+
+	return result, highlightErr
+}
+
+// newCodeFormatter creates the chroma HTML formatter for the options
+func newCodeFormatter(options *HighlightOptions) *chromahtml.Formatter {
+	return chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(options.LineNumbers),
+		chromahtml.TabWidth(4),
+	)
+}
+
+// writeHighlightStylesheet writes the theme's CSS to the output directory
+func writeHighlightStylesheet(outputDir string, options *HighlightOptions) error {
+	var buf bytes.Buffer
+	if err := newCodeFormatter(options).WriteCSS(&buf, styles.Get(options.Theme)); err != nil {
+		return fmt.Errorf("failed to generate highlight stylesheet: %w", err)
+	}
+
+	destPath := filepath.Join(outputDir, HighlightStylesheet)
+	if err := os.WriteFile(destPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write CSS file %s: %w", HighlightStylesheet, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"genny/pkg/utils"
+)
+
+// Region markers select part of an included file: a line containing "#region NAME" starts
+// the region and the next line containing "#endregion" ends it. They work in any comment
+// syntax (// #region setup, <!-- #region setup -->, # #region setup).
+const (
+	regionStart = "#region"
+	regionEnd   = "#endregion"
+)
+
+// include reads a file inside the project root for a template, optionally selecting a line
+// range ("12", "12-20", "12-") or a named region. The file is recorded as a dependency of
+// the build. The text is returned as-is; html/template escapes it.
+func (s *Site) include(name string, selection ...string) (string, error) {
+	path, err := s.projectPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", &FileNotFoundError{Path: name}
+		}
+		return "", fmt.Errorf("include %s: %w", name, err)
+	}
+	s.addDependency(path)
+
+	if len(selection) == 0 || selection[0] == "" {
+		return string(content), nil
+	}
+	if len(selection) > 1 {
+		return "", fmt.Errorf("include %s: expected one line range or region, got %d", name, len(selection))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	selected, err := selectLines(lines, selection[0])
+	if err != nil {
+		return "", fmt.Errorf("include %s: %w", name, err)
+	}
+	return strings.Join(selected, "\n") + "\n", nil
+}
+
+// projectPath resolves a path relative to the project root, rejecting paths (or symlinks)
+// that lead outside it
+func (s *Site) projectPath(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("include %s: path must be relative to the project root", name)
+	}
+
+	root, err := filepath.Abs(s.RootPath)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(root, filepath.FromSlash(name))
+	inside, err := utils.WithinDir(root, path)
+	if err != nil {
+		return "", err
+	}
+	if !inside {
+		return "", fmt.Errorf("include %s: path is outside the project root", name)
+	}
+
+	return path, nil
+}
+
+// selectLines picks a 1-based line range ("12", "12-20", "12-") or the lines of a named
+// region from a file
+func selectLines(lines []string, selection string) ([]string, error) {
+	start, end, isRange, err := parseLineRange(selection, len(lines))
+	if err != nil {
+		return nil, err
+	}
+	if isRange {
+		return lines[start-1 : end], nil
+	}
+
+	var selected []string
+	inRegion, found := false, false
+	for _, line := range lines {
+		switch {
+		case !inRegion && hasRegionMarker(line, regionStart, selection):
+			inRegion, found = true, true
+		case inRegion && strings.Contains(line, regionEnd):
+			inRegion = false
+		case inRegion && strings.Contains(line, regionStart):
+			// Markers of nested regions are left out
+		case inRegion:
+			selected = append(selected, line)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no region '%s' (mark it with %s %s ... %s)", selection, regionStart, selection, regionEnd)
+	}
+	return selected, nil
+}
+
+// hasRegionMarker reports whether a line holds "#region NAME"
+func hasRegionMarker(line, marker, name string) bool {
+	_, rest, ok := strings.Cut(line, marker)
+	if !ok {
+		return false
+	}
+	fields := strings.Fields(rest)
+	return len(fields) > 0 && fields[0] == name
+}
+
+// parseLineRange parses "12", "12-20" or "12-". Anything not starting with a digit is a
+// region name.
+func parseLineRange(selection string, count int) (start, end int, isRange bool, err error) {
+	if selection == "" || selection[0] < '0' || selection[0] > '9' {
+		return 0, 0, false, nil
+	}
+
+	from, to, hasDash := strings.Cut(selection, "-")
+	start, err = strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid line range '%s'", selection)
+	}
+	end = start
+	if hasDash {
+		end = count
+		if to = strings.TrimSpace(to); to != "" {
+			if end, err = strconv.Atoi(to); err != nil {
+				return 0, 0, false, fmt.Errorf("invalid line range '%s'", selection)
+			}
+		}
+	}
+
+	if start < 1 || end < start || end > count {
+		return 0, 0, false, fmt.Errorf("line range '%s' is outside the file's %d lines", selection, count)
+	}
+	return start, end, true, nil
+}
+
+// addDependency records a file the build read besides the project's own sources
+func (s *Site) addDependency(path string) {
+	if s.dependencies == nil {
+		s.dependencies = make(map[string]bool)
+	}
+	s.dependencies[path] = true
+}
+
+// Dependencies returns the extra files the last build read (e.g. through include), for
+// watch mode
+func (s *Site) Dependencies() []string {
+	paths := make([]string, 0, len(s.dependencies))
+	for path := range s.dependencies {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// homeTitle is the title of the site root in breadcrumbs
+const homeTitle = "Home"
+
+// MenuItem is a node of the page tree built from page output paths
+type MenuItem struct {
+	Title    string      // Page title, or a title derived from the path segment
+	URL      string      // URL relative to the site root (empty for directories without a page)
+	Page     *Page       // The page at this node (nil for directories without a page)
+	Order    int         // Sort order from front matter (lower first)
+	Children []*MenuItem // Child nodes sorted by order, then title
+}
+
+// IsActive reports whether this item is the given page (or any page of it, if paginated)
+func (m *MenuItem) IsActive(page *Page) bool {
+	return m.Page != nil && page != nil && m.URL == treeURL(page)
+}
+
+// IsAncestor reports whether the given page is somewhere below this item
+func (m *MenuItem) IsAncestor(page *Page) bool {
+	for _, child := range m.Children {
+		if child.IsActive(page) || child.IsAncestor(page) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildMenu builds the page tree from the published pages' output paths and sets the
+// Breadcrumbs of every page, published or held back. A page's place in the tree comes
+// from its path without the extension, so "news.html", "news/index.html" and the
+// "news/" directory are the same node. Pages with "menu: false" in their front matter
+// are left out of the tree; "order" and "title" set an item's sort order and title.
+func BuildMenu(pages []*Page, heldPages []*Page) []*MenuItem {
+	root := &MenuItem{}
+	nodes := map[string]*MenuItem{"": root}
+
+	var ensure func(key string) *MenuItem
+	ensure = func(key string) *MenuItem {
+		if node, exists := nodes[key]; exists {
+			return node
+		}
+		parentKey := path.Dir(key)
+		if parentKey == "." {
+			parentKey = ""
+		}
+		parent := ensure(parentKey)
+		node := &MenuItem{Title: titleFromSegment(path.Base(key))}
+		parent.Children = append(parent.Children, node)
+		nodes[key] = node
+		return node
+	}
+
+	for _, page := range pages {
+		if hidden, ok := page.Meta["menu"].(bool); ok && !hidden {
+			continue
+		}
+		// Later pages of a paginated page live under the first one
+		if page.Paginator != nil && page.Paginator.PageNumber > 1 {
+			continue
+		}
+
+		node := ensure(menuKey(page.URL()))
+		node.Page = page
+		node.Title = page.Title()
+		node.URL = page.URL()
+		if order, err := strconv.Atoi(fmt.Sprint(page.Meta["order"])); err == nil {
+			node.Order = order
+		}
+	}
+
+	sortMenu(root.Children)
+
+	for _, page := range pages {
+		page.Breadcrumbs = breadcrumbs(page, nodes)
+	}
+	for _, page := range heldPages {
+		page.Breadcrumbs = breadcrumbs(page, nodes)
+	}
+
+	return root.Children
+}
+
+// breadcrumbs builds the chain from the site root to a page
+func breadcrumbs(page *Page, nodes map[string]*MenuItem) []*MenuItem {
+	url := treeURL(page)
+	crumbs := []*MenuItem{{Title: homeTitle, URL: "index.html"}}
+
+	key := menuKey(url)
+	if key == "" {
+		return crumbs
+	}
+
+	segments := strings.Split(key, "/")
+	for i := range segments {
+		prefix := strings.Join(segments[:i+1], "/")
+		if node, exists := nodes[prefix]; exists {
+			crumbs = append(crumbs, node)
+			continue
+		}
+
+		// Not in the tree (hidden or held back) - still show the step
+		crumb := &MenuItem{Title: titleFromSegment(segments[i])}
+		if i == len(segments)-1 {
+			crumb.Title = page.Title()
+			crumb.URL = url
+			crumb.Page = page
+		}
+		crumbs = append(crumbs, crumb)
+	}
+
+	return crumbs
+}
+
+// treeURL returns the URL a page has in the tree: its own, or the first page's URL for
+// later pages of a paginated page
+func treeURL(page *Page) string {
+	if page.Paginator != nil && page.Paginator.PageNumber > 1 {
+		return page.Paginator.FirstURL
+	}
+	return page.URL()
+}
+
+// menuKey converts a page URL to its tree key ("news.html" and "news/" → "news")
+func menuKey(url string) string {
+	url = filepath.ToSlash(url)
+	if path.Base(url) == "index.html" {
+		url = path.Dir(url)
+	}
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".html")
+	if url == "." {
+		return ""
+	}
+	return url
+}
+
+// sortMenu sorts menu items by order, then title, recursively
+func sortMenu(items []*MenuItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Order != items[j].Order {
+			return items[i].Order < items[j].Order
+		}
+		return items[i].Title < items[j].Title
+	})
+	for _, item := range items {
+		sortMenu(item.Children)
+	}
+}
+
+// titleFromSegment derives a title from a path segment ("about-us" → "About us")
+func titleFromSegment(segment string) string {
+	title := strings.NewReplacer("-", " ", "_", " ").Replace(segment)
+	r, size := utf8.DecodeRuneInString(title)
+	if r == utf8.RuneError {
+		return title
+	}
+	return string(unicode.ToUpper(r)) + title[size:]
+}
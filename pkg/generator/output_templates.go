@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// OutputTemplateExt marks a template for a non-page output file
+const OutputTemplateExt = ".tmpl"
+
+// xmlEscaper escapes the characters that are markup in XML text and attribute values
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+// GenerateOutputs renders the non-page output templates. Templates for .html files use
+// html/template; anything else (XML feeds, JSON, text) uses text/template, which writes
+// values unescaped, so templates escape them for their format: {{ .title | xmlEscape }}
+// in XML, {{ jsonify . }} in JSON. Outputs get the same data as pages, without .Page,
+// and no header or footer.
+func (g *MainSiteGenerator) GenerateOutputs(site *Site) error {
+	pagePaths := make(map[string]bool, len(site.Pages))
+	for _, page := range site.Pages {
+		pagePaths[filepath.ToSlash(page.OutputPath)] = true
+	}
+
+	for _, output := range site.Outputs {
+		if pagePaths[filepath.ToSlash(output.OutputPath)] {
+			return fmt.Errorf("output template %s: %s is also generated by a page", output.SourcePath, output.OutputPath)
+		}
+		if err := g.generateOutput(output, site); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", output.OutputPath, err)
+		}
+	}
+	return nil
+}
+
+// generateOutput renders a single output template
+func (g *MainSiteGenerator) generateOutput(output *OutputTemplate, site *Site) error {
+	name := filepath.ToSlash(output.OutputPath) + OutputTemplateExt
+	funcs := siteFuncs(site, strings.Repeat("../", pageDepth(output.OutputPath)), false)
+
+	var buf bytes.Buffer
+	switch strings.ToLower(filepath.Ext(output.OutputPath)) {
+	case ".html", ".htm":
+		t, err := NewTemplate(name, g.strict).Funcs(funcs).Parse(output.Content)
+		if err != nil {
+			return &TemplateParseError{Name: name, Source: output.Content, Err: err}
+		}
+		if err := t.Execute(&buf, pageData(site, nil)); err != nil {
+			return executeError(name, err)
+		}
+
+	default:
+		t := texttemplate.New(name).
+			Funcs(texttemplate.FuncMap(templateFuncs())).
+			Funcs(texttemplate.FuncMap(funcs)).
+			Funcs(texttemplate.FuncMap{"xmlEscape": xmlEscape})
+		if g.strict {
+			t.Option("missingkey=error")
+		}
+		if _, err := t.Parse(output.Content); err != nil {
+			return &TemplateParseError{Name: name, Source: output.Content, Err: err}
+		}
+		if err := t.Execute(&buf, pageData(site, nil)); err != nil {
+			return executeError(name, err)
+		}
+	}
+
+	outputPath := filepath.Join(g.outputDir, output.OutputPath)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", filepath.Dir(outputPath), err)
+	}
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// xmlEscape escapes a value for XML text or a quoted attribute, for output templates that
+// text/template renders unescaped: <title>{{ .title | xmlEscape }}</title>
+func xmlEscape(value interface{}) string {
+	return xmlEscaper.Replace(fmt.Sprint(value))
+}
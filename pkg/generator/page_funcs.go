@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fingerprintLength is the number of hex characters of the content hash in fingerprinted
+// asset names (logo.3f2a9c1e.png)
+const fingerprintLength = 8
+
+// PageFuncs returns the template functions that depend on the site and on where the
+// output is written: URLs are relative to the page (or, for previews, to www/preview/).
+// The main index and component previews have no page.
+func PageFuncs(site *Site, page *Page, preview bool) template.FuncMap {
+	funcs := siteFuncs(site, rootPrefix(page, preview), preview)
+	funcs["toc"] = tocFunc
+	return funcs
+}
+
+// siteFuncs returns the site-bound template functions for an output file that reaches
+// the site root through prefix
+func siteFuncs(site *Site, prefix string, preview bool) template.FuncMap {
+	return template.FuncMap{
+		"asset": func(name string, options ...string) (string, error) {
+			return site.assetURL(name, prefix, preview, options)
+		},
+		"pageURL": func(name string) (string, error) {
+			return site.pageURL(name, prefix, preview)
+		},
+		"relURL": func(target string) string {
+			if isExternalURL(target) {
+				return target
+			}
+			return prefix + strings.TrimPrefix(target, "/")
+		},
+		"include": site.include,
+	}
+}
+
+// isExternalURL reports whether a link target needs no root prefix: an absolute URL
+// ("https://...", "mailto:..."), a protocol-relative URL ("//cdn...") or a fragment ("#top")
+func isExternalURL(target string) bool {
+	if strings.HasPrefix(target, "#") || strings.HasPrefix(target, "//") {
+		return true
+	}
+	colon := strings.IndexByte(target, ':')
+	return colon > 0 && !strings.ContainsAny(target[:colon], "/?#")
+}
+
+// rootPrefix returns the relative path from an output file back to the site root
+func rootPrefix(page *Page, preview bool) string {
+	if preview {
+		// Previews are written to www/preview/ and link to the project sources
+		return "../../"
+	}
+	if page == nil {
+		return ""
+	}
+	return strings.Repeat("../", pageDepth(page.OutputPath))
+}
+
+// assetURL resolves an asset name (relative to assets/, e.g. "img/logo.png") to its URL
+// from a page. With the "fingerprint" option, it returns the asset's content-hashed name,
+// which CopyAssets also writes. Previews link to the source assets, which have no
+// fingerprinted copies.
+func (s *Site) assetURL(name, prefix string, preview bool, options []string) (string, error) {
+	fingerprint := false
+	for _, option := range options {
+		if option != "fingerprint" {
+			return "", fmt.Errorf("asset %s: unknown option '%s' (expected \"fingerprint\")", name, option)
+		}
+		fingerprint = true
+	}
+
+	key := path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "/"))
+	if !strings.HasPrefix(key, "assets/") {
+		key = "assets/" + key
+	}
+
+	for i := range s.Assets {
+		asset := &s.Assets[i]
+		if filepath.ToSlash(asset.OutputPath) != key {
+			continue
+		}
+		if !fingerprint || preview {
+			return prefix + key, nil
+		}
+		if asset.FingerprintPath == "" {
+			fingerprinted, err := fingerprintPath(asset)
+			if err != nil {
+				return "", err
+			}
+			asset.FingerprintPath = fingerprinted
+		}
+		return prefix + filepath.ToSlash(asset.FingerprintPath), nil
+	}
+
+	return "", &FileNotFoundError{Path: key}
+}
+
+// pageURL resolves a page, by front matter id or by source name, to its URL from a page.
+// Source names are the source path without .html ("about", "docs/intro") or, for
+// index.html files, their directory ("paz"); "index" is the main index. Previews link to
+// the target's preview.
+func (s *Site) pageURL(name, prefix string, preview bool) (string, error) {
+	key := strings.Trim(strings.TrimSuffix(filepath.ToSlash(name), ".html"), "/")
+	// Preview links start with "./" so AdjustPathsForPreview leaves them alone
+	if key == "index" || key == "" {
+		if preview {
+			return "./index.html", nil
+		}
+		return prefix + "index.html", nil
+	}
+
+	candidates := s.pagesByName()[key]
+	// Later chunks of a paginated page share its source; link to the first
+	var pages []*Page
+	for _, page := range candidates {
+		if page.Paginator == nil || page.Paginator.PageNumber == 1 {
+			pages = append(pages, page)
+		}
+	}
+
+	switch len(pages) {
+	case 0:
+		return "", &ValidationError{Field: "pageURL", Message: fmt.Sprintf("no page named '%s' (use a source name like \"about\" or a front matter id)", name)}
+	case 1:
+	default:
+		outputs := make([]string, len(pages))
+		for i, page := range pages {
+			outputs[i] = page.OutputPath
+		}
+		return "", &ValidationError{Field: "pageURL", Message: fmt.Sprintf("'%s' matches %d pages (%s); give one of them a front matter id", name, len(pages), strings.Join(outputs, ", "))}
+	}
+
+	page := pages[0]
+	if preview {
+		return "./" + previewName(page), nil
+	}
+	for _, held := range s.HeldPages {
+		if held == page {
+			return "", &ValidationError{Field: "pageURL", Message: fmt.Sprintf("page '%s' (%s) is not published", name, page.OutputPath)}
+		}
+	}
+	return prefix + page.URL(), nil
+}
+
+// pagesByName indexes all pages by front matter id and source name for pageURL
+func (s *Site) pagesByName() map[string][]*Page {
+	if s.pageIndex != nil {
+		return s.pageIndex
+	}
+
+	s.pageIndex = make(map[string][]*Page)
+	for _, page := range s.AllPages() {
+		keys := make(map[string]bool)
+		if id, ok := page.Meta["id"]; ok {
+			keys[fmt.Sprint(id)] = true
+		}
+		if rel, err := filepath.Rel(s.RootPath, page.SourcePath); err == nil {
+			source := strings.TrimSuffix(filepath.ToSlash(rel), ".html")
+			keys[source] = true
+			if dir, base := path.Split(source); base == "index" && dir != "" {
+				keys[strings.TrimSuffix(dir, "/")] = true
+			}
+		}
+		for key := range keys {
+			s.pageIndex[key] = append(s.pageIndex[key], page)
+		}
+	}
+	return s.pageIndex
+}
+
+// fingerprintPath returns an asset's output path with a hash of its content before the
+// extension
+func fingerprintPath(asset *Asset) (string, error) {
+	content, err := os.ReadFile(asset.SourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read asset %s: %w", asset.SourcePath, err)
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])[:fingerprintLength]
+
+	ext := filepath.Ext(asset.OutputPath)
+	return strings.TrimSuffix(asset.OutputPath, ext) + "." + hash + ext, nil
+}
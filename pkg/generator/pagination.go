@@ -0,0 +1,200 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultPageSize is used when a paginated page doesn't configure a size
+const defaultPageSize = 10
+
+// Paginator holds one page's slice of a paginated data collection
+type Paginator struct {
+	Items      []interface{} // Items on this page
+	PageNumber int           // Current page number (1-based)
+	PageSize   int           // Maximum number of items per page
+	TotalPages int           // Number of pages in the collection
+	TotalItems int           // Number of items in the collection
+	FirstURL   string        // URL of the first page
+	LastURL    string        // URL of the last page
+	PrevURL    string        // URL of the previous page (empty on the first page)
+	NextURL    string        // URL of the next page (empty on the last page)
+}
+
+// HasPrev reports whether there is a previous page
+func (p *Paginator) HasPrev() bool {
+	return p.PrevURL != ""
+}
+
+// HasNext reports whether there is a next page
+func (p *Paginator) HasNext() bool {
+	return p.NextURL != ""
+}
+
+// ExpandPaginatedPages splits every page that paginates a data collection into one page
+// per chunk of items. Pagination is configured in the page's front matter:
+//
+//	---
+//	paginate:
+//	  collection: .news.items
+//	  size: 10
+//	  path: /news/page/:num/
+//	---
+//
+// The first page keeps the page's own output path; later pages are written to the
+// path pattern with ":num" replaced by the page number. Every generated page gets a
+// Paginator. URLs are relative to the site root, like any other link in a page.
+func ExpandPaginatedPages(pages []*Page, data DataContext) ([]*Page, error) {
+	var result []*Page
+
+	for _, page := range pages {
+		config, ok := page.Meta["paginate"].(map[string]interface{})
+		if !ok {
+			result = append(result, page)
+			continue
+		}
+
+		expanded, err := paginatePage(page, config, data)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, expanded...)
+	}
+
+	return result, nil
+}
+
+// paginatePage generates the pages for a single paginated page
+func paginatePage(page *Page, config map[string]interface{}, data DataContext) ([]*Page, error) {
+	collectionPath, _ := config["collection"].(string)
+	if collectionPath == "" {
+		return nil, &ValidationError{
+			Field:   page.SourcePath,
+			Message: "paginate has no 'collection' data path",
+		}
+	}
+	if !strings.HasPrefix(collectionPath, ".") {
+		collectionPath = "." + collectionPath
+	}
+
+	size := defaultPageSize
+	if value, exists := config["size"]; exists {
+		n, err := strconv.Atoi(fmt.Sprint(value))
+		if err != nil || n < 1 {
+			return nil, &ValidationError{
+				Field:   page.SourcePath,
+				Message: fmt.Sprintf("paginate size must be a positive integer, got '%v'", value),
+			}
+		}
+		size = n
+	}
+
+	pattern, _ := config["path"].(string)
+	if pattern == "" {
+		pattern = defaultPaginationPattern(page.OutputPath)
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, ":num") {
+		return nil, &ValidationError{
+			Field:   page.SourcePath,
+			Message: fmt.Sprintf("paginate path '%s' has no :num placeholder", pattern),
+		}
+	}
+
+	value, err := data.Get(collectionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paginated collection for page %s: %w", page.SourcePath, err)
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, &ValidationError{
+			Field:   page.SourcePath,
+			Message: fmt.Sprintf("paginated collection %s is a %T, not a list", collectionPath, value),
+		}
+	}
+
+	totalPages := (len(items) + size - 1) / size
+	if totalPages == 0 {
+		// An empty collection still renders the first page
+		totalPages = 1
+	}
+
+	outputPaths := make([]string, totalPages)
+	urls := make([]string, totalPages)
+	for i := range outputPaths {
+		if i == 0 {
+			outputPaths[i] = page.OutputPath
+		} else {
+			outputPaths[i] = paginationOutputPath(pattern, i+1)
+		}
+		urls[i] = outputURL(outputPaths[i])
+	}
+
+	pages := make([]*Page, totalPages)
+	for i := range pages {
+		start := i * size
+		end := min(start+size, len(items))
+
+		paginator := &Paginator{
+			Items:      items[start:end],
+			PageNumber: i + 1,
+			PageSize:   size,
+			TotalPages: totalPages,
+			TotalItems: len(items),
+			FirstURL:   urls[0],
+			LastURL:    urls[totalPages-1],
+		}
+		if i > 0 {
+			paginator.PrevURL = urls[i-1]
+		}
+		if i < totalPages-1 {
+			paginator.NextURL = urls[i+1]
+		}
+
+		generated := *page
+		generated.OutputPath = outputPaths[i]
+		generated.Paginator = paginator
+		pages[i] = &generated
+	}
+
+	return pages, nil
+}
+
+// defaultPaginationPattern derives "<page>/page/:num/" from a page's output path
+func defaultPaginationPattern(outputPath string) string {
+	outputPath = filepath.ToSlash(outputPath)
+	base := strings.TrimSuffix(outputPath, ".html")
+	if path.Base(outputPath) == "index.html" {
+		base = path.Dir(outputPath)
+	}
+	if base == "." {
+		return "page/:num/"
+	}
+	return base + "/page/:num/"
+}
+
+// paginationOutputPath builds the output file path for a page number from a pattern
+func paginationOutputPath(pattern string, number int) string {
+	outputPath := strings.ReplaceAll(pattern, ":num", strconv.Itoa(number))
+	if strings.HasSuffix(outputPath, "/") || path.Ext(outputPath) == "" {
+		outputPath = path.Join(outputPath, "index.html")
+	}
+	return filepath.FromSlash(path.Clean(outputPath))
+}
+
+// outputURL returns the root-relative URL of an output path, using the directory
+// form ("news/page/2/") for index.html files
+func outputURL(outputPath string) string {
+	outputPath = filepath.ToSlash(outputPath)
+	if path.Base(outputPath) == "index.html" {
+		dir := path.Dir(outputPath)
+		if dir == "." {
+			return "index.html"
+		}
+		return dir + "/"
+	}
+	return outputPath
+}
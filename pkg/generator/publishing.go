@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the accepted formats for date values written as strings in front matter
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// IsDraft reports whether the page's front matter marks it as a draft
+func (p *Page) IsDraft() bool {
+	draft, _ := p.Meta["draft"].(bool)
+	return draft
+}
+
+// PublishDate returns the page's publishDate from front matter, if it has a valid one
+func (p *Page) PublishDate() (time.Time, bool) {
+	date, ok, err := metaTime(p.Meta, "publishDate")
+	return date, ok && err == nil
+}
+
+// HoldReason describes why a page is kept out of the published site, or returns ""
+// if the page is published
+func (p *Page) HoldReason(now time.Time) string {
+	if p.IsDraft() {
+		return "draft"
+	}
+	if date, ok := p.PublishDate(); ok && date.After(now) {
+		return fmt.Sprintf("scheduled for %s", date.Format("2006-01-02 15:04"))
+	}
+	return ""
+}
+
+// FilterPublished splits pages into the published ones and the ones held back because
+// they are drafts (draft: true) or scheduled for a future publishDate. With includeDrafts
+// every page is published. An unparseable publishDate is an error.
+func FilterPublished(pages []*Page, now time.Time, includeDrafts bool) ([]*Page, []*Page, error) {
+	var published, held []*Page
+
+	for _, page := range pages {
+		if _, _, err := metaTime(page.Meta, "publishDate"); err != nil {
+			return nil, nil, &ValidationError{Field: page.SourcePath, Message: err.Error()}
+		}
+
+		if !includeDrafts && page.HoldReason(now) != "" {
+			held = append(held, page)
+			continue
+		}
+		published = append(published, page)
+	}
+
+	return published, held, nil
+}
+
+// metaTime reads a front matter value as a time, accepting YAML timestamps and date strings
+func metaTime(meta map[string]interface{}, key string) (time.Time, bool, error) {
+	switch value := meta[key].(type) {
+	case nil:
+		return time.Time{}, false, nil
+	case time.Time:
+		return value, true, nil
+	case string:
+		value = strings.TrimSpace(value)
+		for _, layout := range dateLayouts {
+			if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return date, true, nil
+			}
+		}
+		return time.Time{}, false, fmt.Errorf("invalid %s '%s'", key, value)
+	default:
+		return time.Time{}, false, fmt.Errorf("invalid %s '%v'", key, value)
+	}
+}
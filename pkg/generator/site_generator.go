@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"genny/pkg/encrypt"
 	"genny/pkg/utils"
@@ -14,11 +15,13 @@ import (
 // MainSiteGenerator handles generating the main site pages
 type MainSiteGenerator struct {
 	outputDir string
+	strict    bool
 }
 
-// NewMainSiteGenerator creates a new MainSiteGenerator
-func NewMainSiteGenerator(outputDir string) *MainSiteGenerator {
-	return &MainSiteGenerator{outputDir: outputDir}
+// NewMainSiteGenerator creates a new MainSiteGenerator. In strict mode, templates that read
+// missing data fail the build.
+func NewMainSiteGenerator(outputDir string, strict bool) *MainSiteGenerator {
+	return &MainSiteGenerator{outputDir: outputDir, strict: strict}
 }
 
 // GenerateMainSite generates the main site using the index template
@@ -29,64 +32,17 @@ func (g *MainSiteGenerator) GenerateMainSite(site *Site, mainTemplateContent str
 	}
 
 	// Create a template set with all components, header, and footer
-	t := template.New("Main")
-
-	// Parse the main template first
-	_, err := t.Parse(mainTemplateContent)
+	t, err := parsePageTemplate("Main", mainTemplateContent, site, nil, false, headerContent, footerContent, g.strict)
 	if err != nil {
-		return &TemplateParseError{
-			Name:   "Main",
-			Source: mainTemplateContent,
-			Err:    err,
-		}
-	}
-
-	// Add components
-	for name, comp := range site.Components {
-		_, err := t.New(name).Parse(comp.Template)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   name,
-				Source: comp.Template,
-				Err:    err,
-			}
-		}
-	}
-
-	// Add header and footer if they exist
-	if headerContent != "" {
-		_, err := t.New("header.html").Parse(headerContent)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   "header.html",
-				Source: headerContent,
-				Err:    err,
-			}
-		}
-	}
-
-	if footerContent != "" {
-		_, err := t.New("footer.html").Parse(footerContent)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   "footer.html",
-				Source: footerContent,
-				Err:    err,
-			}
-		}
+		return err
 	}
 
-	// Execute the main template with all data
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, site.Data.GetAll()); err != nil {
-		return &TemplateExecuteError{
-			Name: "Main",
-			Err:  err,
-		}
+	// Render the template with all data and clean up excessive whitespace
+	rendered, err := renderPage(t, site, nil, "index.html")
+	if err != nil {
+		return err
 	}
-
-	// Clean up excessive whitespace
-	cleaned := utils.CleanupWhitespace(buf.String())
+	cleaned := utils.CleanupWhitespace(rendered)
 
 	// Write to index.html in output directory
 	outputPath := filepath.Join(g.outputDir, "index.html")
@@ -107,85 +63,65 @@ func (g *MainSiteGenerator) GeneratePages(site *Site, headerContent, footerConte
 	return nil
 }
 
-// generatePage generates a single page
-func (g *MainSiteGenerator) generatePage(page *Page, site *Site, headerContent, footerContent string) error {
-	// Create a template set with all components, header, and footer
-	t := template.New("Page")
-
-	// Parse the page content
-	_, err := t.Parse(page.Content)
-	if err != nil {
-		return &TemplateParseError{
-			Name:   page.OutputPath,
-			Source: page.Content,
-			Err:    err,
-		}
+// ScanPages renders every page once without writing it and sets its TOC and Summary, so
+// they are known before the first page is generated: a page can read its own .Page.TOC,
+// and listings and output templates the TOC and summary of other pages. Held back pages
+// are rendered as previews, the only output they get. An encrypted page keeps its
+// headings to itself: its TOC is only set while it is rendered.
+func (g *MainSiteGenerator) ScanPages(site *Site, headerContent, footerContent string) error {
+	pages := site.AllPages()
+	for _, page := range pages {
+		page.TOC = nil
+		page.headings = nil
 	}
 
-	// Add components
-	for name, comp := range site.Components {
-		_, err := t.New(name).Parse(comp.Template)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   name,
-				Source: comp.Template,
-				Err:    err,
-			}
-		}
+	held := make(map[*Page]bool, len(site.HeldPages))
+	for _, page := range site.HeldPages {
+		held[page] = true
 	}
 
-	// Add header and footer if they exist
-	if headerContent != "" {
-		_, err := t.New("header.html").Parse(headerContent)
+	// Mark where the page's own content starts and ends, and where a <!--more--> was
+	header := headerContent + bodyMarker
+	footer := bodyMarker + footerContent
+	words := summaryWords(site)
+
+	for _, page := range pages {
+		content := strings.Replace(page.Content, MoreMarker, moreMarker, 1)
+		t, err := parsePageTemplate(page.OutputPath, content, site, page, held[page], header, footer, g.strict)
 		if err != nil {
-			return &TemplateParseError{
-				Name:   "header.html",
-				Source: headerContent,
-				Err:    err,
-			}
+			return fmt.Errorf("failed to render page %s: %w", page.OutputPath, err)
 		}
-	}
-
-	if footerContent != "" {
-		_, err := t.New("footer.html").Parse(footerContent)
+		rendered, headings, err := executePage(t, site, page, page.OutputPath)
 		if err != nil {
-			return &TemplateParseError{
-				Name:   "footer.html",
-				Source: footerContent,
-				Err:    err,
-			}
+			return fmt.Errorf("failed to render page %s: %w", page.OutputPath, err)
 		}
-	}
 
-	// Execute the page template with all data
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, site.Data.GetAll()); err != nil {
-		return &TemplateExecuteError{
-			Name: page.OutputPath,
-			Err:  err,
+		page.headings = headings
+		if page.EncryptKey == "" {
+			page.TOC = headings
 		}
+		page.Summary = summarize(page, pageBody(rendered), words)
 	}
+	return nil
+}
 
-	// Clean up excessive whitespace
-	cleaned := utils.CleanupWhitespace(buf.String())
+// generatePage generates a single page
+func (g *MainSiteGenerator) generatePage(page *Page, site *Site, headerContent, footerContent string) error {
+	// Create a template set with all components, header, and footer
+	t, err := parsePageTemplate(page.OutputPath, page.Content, site, page, false, headerContent, footerContent, g.strict)
+	if err != nil {
+		return err
+	}
 
-	// Adjust paths based on directory depth
-	// Calculate depth by counting path separators in the output path (excluding the filename)
-	dir := filepath.Dir(page.OutputPath)
-	depth := 0
-	if dir != "." {
-		depth = len(filepath.SplitList(dir))
-		if depth == 0 {
-			// On Windows, SplitList might not work as expected, count separators manually
-			for _, char := range dir {
-				if char == '/' || char == filepath.Separator {
-					depth++
-				}
-			}
-		}
+	// Render the template with all data and clean up excessive whitespace
+	rendered, err := renderPage(t, site, page, page.OutputPath)
+	if err != nil {
+		return err
 	}
+	cleaned := utils.CleanupWhitespace(rendered)
 
-	if depth > 0 {
+	// Adjust paths based on directory depth
+	if depth := pageDepth(page.OutputPath); depth > 0 {
 		cleaned = AdjustPathsForDepth(cleaned, depth)
 	}
 
@@ -196,8 +132,15 @@ func (g *MainSiteGenerator) generatePage(page *Page, site *Site, headerContent,
 			return fmt.Errorf("failed to encrypt page %s: %w", page.OutputPath, err)
 		}
 
-		// Read the decrypt form body from decrypt.html at site root
-		decryptFormHTML, err := utils.ExtractBodyContent(filepath.Join(site.RootPath, "decrypt.html"))
+		// Read the decrypt form body from decrypt.html at site root, or the default one
+		// when the project has none yet (a check doesn't create it)
+		decryptPath := filepath.Join(site.RootPath, "decrypt.html")
+		var decryptFormHTML string
+		if _, statErr := os.Stat(decryptPath); os.IsNotExist(statErr) {
+			decryptFormHTML, err = utils.ExtractBody(encrypt.DefaultDecryptPageHTML)
+		} else {
+			decryptFormHTML, err = utils.ExtractBodyContent(decryptPath)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to read decrypt template: %w", err)
 		}
@@ -228,46 +171,17 @@ func (g *MainSiteGenerator) GenerateMainSitePreview(site *Site, mainTemplateCont
 	}
 
 	// Create a template set with all components, header, and footer
-	t := template.New("Main")
-
-	_, err := t.Parse(mainTemplateContent)
+	t, err := parsePageTemplate("Main", mainTemplateContent, site, nil, true, headerContent, footerContent, g.strict)
 	if err != nil {
-		return &TemplateParseError{
-			Name:   "Main",
-			Source: mainTemplateContent,
-			Err:    err,
-		}
-	}
-
-	for name, comp := range site.Components {
-		_, err := t.New(name).Parse(comp.Template)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   name,
-				Source: comp.Template,
-				Err:    err,
-			}
-		}
+		return err
 	}
 
-	if headerContent != "" {
-		if _, err := t.New("header.html").Parse(headerContent); err != nil {
-			return &TemplateParseError{Name: "header.html", Source: headerContent, Err: err}
-		}
-	}
-
-	if footerContent != "" {
-		if _, err := t.New("footer.html").Parse(footerContent); err != nil {
-			return &TemplateParseError{Name: "footer.html", Source: footerContent, Err: err}
-		}
-	}
-
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, site.Data.GetAll()); err != nil {
-		return &TemplateExecuteError{Name: "Main", Err: err}
+	// Render the template with all data and clean up excessive whitespace
+	rendered, err := renderPage(t, site, nil, "index.html")
+	if err != nil {
+		return err
 	}
-
-	cleaned := utils.CleanupWhitespace(buf.String())
+	cleaned := utils.CleanupWhitespace(rendered)
 	cleaned = AdjustPathsForPreview(cleaned)
 
 	outputPath := filepath.Join(previewDir, "index.html")
@@ -278,14 +192,15 @@ func (g *MainSiteGenerator) GenerateMainSitePreview(site *Site, mainTemplateCont
 	return nil
 }
 
-// GeneratePagePreviews generates preview pages for all pages in the preview directory
+// GeneratePagePreviews generates preview pages for all pages, including held back drafts
+// and scheduled pages, in the preview directory
 func (g *MainSiteGenerator) GeneratePagePreviews(site *Site, headerContent, footerContent string, previewDir string) error {
 	// Ensure preview directory exists
 	if err := os.MkdirAll(previewDir, 0755); err != nil {
 		return fmt.Errorf("failed to create preview directory: %w", err)
 	}
 
-	for _, page := range site.Pages {
+	for _, page := range site.AllPages() {
 		if err := g.generatePagePreview(page, site, headerContent, footerContent, previewDir); err != nil {
 			return fmt.Errorf("failed to generate preview for page %s: %w", page.OutputPath, err)
 		}
@@ -296,84 +211,132 @@ func (g *MainSiteGenerator) GeneratePagePreviews(site *Site, headerContent, foot
 // generatePagePreview generates a single page preview
 func (g *MainSiteGenerator) generatePagePreview(page *Page, site *Site, headerContent, footerContent string, previewDir string) error {
 	// Create a template set with all components, header, and footer
-	t := template.New("Page")
+	t, err := parsePageTemplate(page.OutputPath, page.Content, site, page, true, headerContent, footerContent, g.strict)
+	if err != nil {
+		return err
+	}
 
-	// Parse the page content
-	_, err := t.Parse(page.Content)
+	// Render the template with all data and clean up excessive whitespace
+	rendered, err := renderPage(t, site, page, page.OutputPath)
 	if err != nil {
-		return &TemplateParseError{
-			Name:   page.OutputPath,
-			Source: page.Content,
-			Err:    err,
-		}
+		return err
 	}
+	cleaned := utils.CleanupWhitespace(rendered)
 
-	// Add components
-	for name, comp := range site.Components {
-		_, err := t.New(name).Parse(comp.Template)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   name,
-				Source: comp.Template,
-				Err:    err,
-			}
-		}
+	// Adjust paths for preview directory (same as component previews)
+	cleaned = AdjustPathsForPreview(cleaned)
+
+	outputPath := filepath.Join(previewDir, previewName(page))
+	if err := os.WriteFile(outputPath, []byte(cleaned), 0644); err != nil {
+		return fmt.Errorf("failed to write page preview file: %w", err)
 	}
 
-	// Add header and footer if they exist
-	if headerContent != "" {
-		_, err := t.New("header.html").Parse(headerContent)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   "header.html",
-				Source: headerContent,
-				Err:    err,
-			}
-		}
+	return nil
+}
+
+// renderPage executes a page's template set and post-processes the HTML: code blocks are
+// highlighted, h2–h4 headings get anchors, and the tables of contents placed by the toc
+// function are filled in from the headings. Pages read their TOC as set by ScanPages; the
+// main index has no page.
+func renderPage(t *template.Template, site *Site, page *Page, name string) (string, error) {
+	// An encrypted page sees its own headings, but only while it is rendered
+	if page != nil && page.EncryptKey != "" {
+		page.TOC = page.headings
+		defer func() { page.TOC = nil }()
 	}
 
-	if footerContent != "" {
-		_, err := t.New("footer.html").Parse(footerContent)
-		if err != nil {
-			return &TemplateParseError{
-				Name:   "footer.html",
-				Source: footerContent,
-				Err:    err,
-			}
-		}
+	rendered, toc, err := executePage(t, site, page, name)
+	if err != nil {
+		return "", err
 	}
+	return injectTOC(rendered, t, toc)
+}
 
-	// Execute the page template with all data
+// executePage executes a page's template set once, returning the post-processed HTML and
+// its headings
+func executePage(t *template.Template, site *Site, page *Page, name string) (string, []*TOCEntry, error) {
 	var buf bytes.Buffer
-	if err := t.Execute(&buf, site.Data.GetAll()); err != nil {
-		return &TemplateExecuteError{
-			Name: page.OutputPath,
-			Err:  err,
-		}
+	if err := t.Execute(&buf, pageData(site, page)); err != nil {
+		return "", nil, executeError(name, err)
 	}
 
-	// Clean up excessive whitespace
-	cleaned := utils.CleanupWhitespace(buf.String())
+	highlighted, err := HighlightCode(buf.String(), site.Highlight)
+	if err != nil {
+		return "", nil, err
+	}
 
-	// Adjust paths for preview directory (same as component previews)
-	cleaned = AdjustPathsForPreview(cleaned)
+	anchored, toc := addHeadingAnchors(highlighted)
+	return anchored, toc, nil
+}
 
+// previewName returns the flat preview filename for a page
+func previewName(page *Page) string {
 	// Use the base filename for the preview (e.g., "google.html" not "subdir/index.html")
-	previewName := filepath.Base(page.OutputPath)
-	// For subdirectory pages, use the directory name instead
-	if previewName == "index.html" {
-		dir := filepath.Dir(page.OutputPath)
-		previewName = filepath.Base(dir) + ".html"
+	name := filepath.Base(page.OutputPath)
+	dir := filepath.Dir(page.OutputPath)
+
+	// For subdirectory pages, use the directory path instead (e.g., "paz.html", "news-page-2.html")
+	if name == "index.html" {
+		return strings.ReplaceAll(filepath.ToSlash(dir), "/", "-") + ".html"
 	}
 
-	outputPath := filepath.Join(previewDir, previewName)
-	if err := os.WriteFile(outputPath, []byte(cleaned), 0644); err != nil {
-		return fmt.Errorf("failed to write page preview file: %w", err)
+	// For generated pages in subdirectories, flatten the path (e.g., "projects-alpha.html")
+	if dir != "." {
+		return strings.ReplaceAll(filepath.ToSlash(page.OutputPath), "/", "-")
 	}
 
+	return name
+}
+
+// pageDepth returns the number of directories between the output root and a page
+func pageDepth(outputPath string) int {
+	dir := filepath.ToSlash(filepath.Dir(outputPath))
+	if dir == "." {
+		return 0
+	}
+	return len(strings.Split(dir, "/"))
+}
+
+// ReservedDataKeys are the template data keys genny sets on every page. A data namespace
+// with one of these names would be hidden by them.
+var ReservedDataKeys = []string{"Site", "Page", "Item", "Paginator", "Env", "Build"}
+
+// CheckReservedKeys returns an error if a top-level data namespace uses a reserved key
+func CheckReservedKeys(data map[string]interface{}) error {
+	for _, key := range ReservedDataKeys {
+		if _, exists := data[key]; exists {
+			return &ValidationError{
+				Field:   "data/" + key,
+				Message: fmt.Sprintf("data namespace '%s' is reserved for templates (reserved: %s); rename the file", key, strings.Join(ReservedDataKeys, ", ")),
+			}
+		}
+	}
 	return nil
 }
 
+// pageData builds the template data for a page: all loaded data, plus the site as .Site,
+// the page itself as .Page, the bound item of collection and taxonomy pages as .Item,
+// the current chunk of paginated pages as .Paginator, the allowlisted environment
+// variables as .Env and the build details as .Build. The main index has no page.
+func pageData(site *Site, page *Page) map[string]interface{} {
+	all := site.Data.GetAll()
+	data := make(map[string]interface{}, len(all)+6)
+	for key, value := range all {
+		data[key] = value
+	}
+	data["Site"] = site
+	data["Page"] = page
+	data["Item"] = nil
+	data["Paginator"] = nil
+	data["Env"] = site.Env
+	data["Build"] = site.Build
+	if page != nil {
+		data["Item"] = page.DataContext
+		data["Paginator"] = page.Paginator
+	}
+	return data
+}
+
 // CopyAssets copies static assets to the output directory
 func (g *MainSiteGenerator) CopyAssets(assets []Asset) error {
 	for _, asset := range assets {
@@ -393,13 +356,28 @@ func (g *MainSiteGenerator) CopyAssets(assets []Asset) error {
 		if err := os.WriteFile(destPath, content, 0644); err != nil {
 			return fmt.Errorf("failed to write asset %s: %w", destPath, err)
 		}
+
+		// Write the fingerprinted copy too if a template used it
+		if asset.FingerprintPath != "" {
+			destPath := filepath.Join(g.outputDir, asset.FingerprintPath)
+			if err := os.WriteFile(destPath, content, 0644); err != nil {
+				return fmt.Errorf("failed to write asset %s: %w", destPath, err)
+			}
+		}
 	}
 
 	return nil
 }
 
-// CopyStylesheet copies all CSS files to the output directory
-func (g *MainSiteGenerator) CopyStylesheet(rootPath string) error {
+// CopyStylesheet copies all CSS files to the output directory, and writes the highlighting
+// theme's stylesheet when highlighting is enabled
+func (g *MainSiteGenerator) CopyStylesheet(rootPath string, highlight *HighlightOptions) error {
+	if highlight != nil {
+		if err := writeHighlightStylesheet(g.outputDir, highlight); err != nil {
+			return err
+		}
+	}
+
 	// Find all CSS files in root directory
 	cssPattern := filepath.Join(rootPath, "*.css")
 	cssFiles, err := filepath.Glob(cssPattern)
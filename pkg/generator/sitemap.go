@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SitemapFile is the sitemap written to the output root
+	SitemapFile = "sitemap.xml"
+	// RobotsFile is the robots.txt written to the output root
+	RobotsFile = "robots.txt"
+)
+
+// sitemapNamespace is the XML namespace of the sitemap protocol
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// changeFrequencies are the changefreq values the sitemap protocol allows
+var changeFrequencies = map[string]bool{
+	"always": true, "hourly": true, "daily": true, "weekly": true,
+	"monthly": true, "yearly": true, "never": true,
+}
+
+// sitemapURLSet is the root element of sitemap.xml
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is one page in sitemap.xml
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// GenerateSitemap writes sitemap.xml, listing the main index and every published page
+// with absolute URLs under the site's base URL, and a robots.txt that keeps crawlers out
+// of the previews and points them to the sitemap. Drafts and scheduled pages (even when
+// -drafts publishes them), encrypted pages and pages with "sitemap: false" in their front
+// matter are left out. Without a
+// base URL, or when an output template already writes the file, nothing is generated.
+func (g *MainSiteGenerator) GenerateSitemap(site *Site) error {
+	if site.BaseURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(site.BaseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &ValidationError{Field: "baseURL", Message: fmt.Sprintf("'%s' is not an absolute http(s) URL", site.BaseURL)}
+	}
+	baseURL := strings.TrimSuffix(site.BaseURL, "/") + "/"
+	basePath := strings.TrimSuffix(parsed.Path, "/") + "/"
+
+	custom := make(map[string]bool, len(site.Outputs))
+	for _, output := range site.Outputs {
+		custom[filepath.ToSlash(output.OutputPath)] = true
+	}
+
+	if !custom[SitemapFile] {
+		urlSet := sitemapURLSet{XMLNS: sitemapNamespace}
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{Loc: baseURL, LastMod: lastMod(site.IndexHistory)})
+		now := time.Now()
+		for _, page := range site.Pages {
+			if page.EncryptKey != "" || page.HoldReason(now) != "" {
+				continue
+			}
+			if include, ok := page.Meta["sitemap"].(bool); ok && !include {
+				continue
+			}
+
+			entry, err := pageSitemapURL(page, baseURL)
+			if err != nil {
+				return err
+			}
+			urlSet.URLs = append(urlSet.URLs, entry)
+		}
+
+		content, err := xml.MarshalIndent(urlSet, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", SitemapFile, err)
+		}
+		content = append([]byte(xml.Header), append(content, '\n')...)
+		if err := os.WriteFile(filepath.Join(g.outputDir, SitemapFile), content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", SitemapFile, err)
+		}
+	}
+
+	if !custom[RobotsFile] {
+		robots := fmt.Sprintf("User-agent: *\nDisallow: %spreview/\n\nSitemap: %s%s\n", basePath, baseURL, SitemapFile)
+		if err := os.WriteFile(filepath.Join(g.outputDir, RobotsFile), []byte(robots), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", RobotsFile, err)
+		}
+	}
+
+	return nil
+}
+
+// pageSitemapURL builds a page's sitemap entry, taking changefreq and priority from its
+// front matter
+func pageSitemapURL(page *Page, baseURL string) (sitemapURL, error) {
+	entry := sitemapURL{
+		Loc:     baseURL + strings.TrimPrefix(page.URL(), "/"),
+		LastMod: lastMod(page.History),
+	}
+
+	if value, ok := page.Meta["changefreq"]; ok {
+		entry.ChangeFreq = strings.ToLower(fmt.Sprint(value))
+		if !changeFrequencies[entry.ChangeFreq] {
+			return entry, &ValidationError{
+				Field:   page.SourcePath,
+				Message: fmt.Sprintf("invalid changefreq '%v' (expected always, hourly, daily, weekly, monthly, yearly or never)", value),
+			}
+		}
+	}
+
+	if value, ok := page.Meta["priority"]; ok {
+		priority, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+		if err != nil || priority < 0 || priority > 1 {
+			return entry, &ValidationError{
+				Field:   page.SourcePath,
+				Message: fmt.Sprintf("invalid priority '%v' (expected a number from 0.0 to 1.0)", value),
+			}
+		}
+		entry.Priority = strconv.FormatFloat(priority, 'f', -1, 64)
+	}
+
+	return entry, nil
+}
+
+// lastMod formats a file's last change date for the sitemap, empty when unknown
+func lastMod(history *FileHistory) string {
+	if history == nil || history.Updated.IsZero() {
+		return ""
+	}
+	return history.Updated.UTC().Format(time.RFC3339)
+}
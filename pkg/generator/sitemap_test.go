@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateSitemapSkipsUnpublishedPages(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	page := func(output string, meta map[string]interface{}) *Page {
+		return &Page{SourcePath: output, OutputPath: output, Meta: meta}
+	}
+
+	// Pages published with -drafts still end up in site.Pages
+	secret := page("secret.html", nil)
+	secret.EncryptKey = "pw"
+	site := &Site{
+		BaseURL: "https://example.com/",
+		Pages: []*Page{
+			page("about.html", nil),
+			page("wip.html", map[string]interface{}{"draft": true}),
+			page("soon.html", map[string]interface{}{"publishDate": future}),
+			page("hidden.html", map[string]interface{}{"sitemap": false}),
+			secret,
+		},
+	}
+
+	g := NewMainSiteGenerator(t.TempDir(), false)
+	if err := g.GenerateSitemap(site); err != nil {
+		t.Fatalf("GenerateSitemap() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.outputDir, SitemapFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sitemap := string(content)
+	if !strings.Contains(sitemap, "<loc>https://example.com/about.html</loc>") {
+		t.Errorf("sitemap is missing about.html:\n%s", sitemap)
+	}
+	for _, output := range []string{"wip.html", "soon.html", "hidden.html", "secret.html"} {
+		if strings.Contains(sitemap, output) {
+			t.Errorf("sitemap lists %s:\n%s", output, sitemap)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package generator
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DefaultSummaryWords is the length of a summary taken from the start of a page's text
+const DefaultSummaryWords = 50
+
+// MoreMarker ends a page's summary where it is written in the page body
+const MoreMarker = "<!--more-->"
+
+const (
+	// bodyMarker marks the start and end of a page's own content in a scanned render,
+	// between the header and the footer
+	bodyMarker = "<genny-body></genny-body>"
+	// moreMarker stands in for MoreMarker in a scanned render, since html/template drops
+	// the comments of template text
+	moreMarker = "<genny-more></genny-more>"
+)
+
+var (
+	// nonTextPattern matches elements whose content isn't page text
+	nonTextPattern = regexp.MustCompile(`(?is)<(script|style|template|noscript)\b.*?</(script|style|template|noscript)\s*>`)
+	// headPattern matches the document head of a page
+	headPattern = regexp.MustCompile(`(?is)<head\b.*?</head\s*>`)
+)
+
+// summaryWords returns the length of summaries taken from page text
+func summaryWords(site *Site) int {
+	if site.SummaryWords <= 0 {
+		return DefaultSummaryWords
+	}
+	return site.SummaryWords
+}
+
+// summarize derives a page's summary from its rendered content, without the header and
+// footer: the bound item's description (for collection pages), the page text before a
+// <!--more--> marker, the front matter description, or the first words of the page text,
+// in that order. Encrypted pages are only summarized by their description, so listings
+// never show their content.
+func summarize(page *Page, body string, words int) string {
+	description, _ := page.Meta["description"].(string)
+	if page.EncryptKey != "" {
+		return strings.TrimSpace(description)
+	}
+	if item, ok := page.DataContext.(map[string]interface{}); ok {
+		if itemDescription, ok := item["description"].(string); ok && strings.TrimSpace(itemDescription) != "" {
+			return strings.TrimSpace(itemDescription)
+		}
+	}
+
+	if before, _, found := strings.Cut(body, moreMarker); found {
+		return pageText(before)
+	}
+	if description != "" {
+		return strings.TrimSpace(description)
+	}
+	return firstWords(pageText(body), words)
+}
+
+// pageBody returns the part of a scanned render between the header and the footer, or
+// all of it when the markers are missing
+func pageBody(rendered string) string {
+	start := strings.Index(rendered, bodyMarker)
+	end := strings.LastIndex(rendered, bodyMarker)
+	if start < 0 || end <= start {
+		return rendered
+	}
+	return rendered[start+len(bodyMarker) : end]
+}
+
+// pageText returns the visible text of rendered HTML, with whitespace collapsed, or
+// nothing when it has no words
+func pageText(content string) string {
+	content = headPattern.ReplaceAllString(content, "")
+	content = nonTextPattern.ReplaceAllString(content, " ")
+	content = tagPattern.ReplaceAllString(content, " ")
+	text := strings.Join(strings.Fields(html.UnescapeString(content)), " ")
+
+	if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return ""
+	}
+	return text
+}
+
+// firstWords returns the first count words of text, ending with an ellipsis when any were cut
+func firstWords(text string, count int) string {
+	words := strings.Fields(text)
+	if len(words) <= count {
+		return text
+	}
+	return strings.Join(words[:count], " ") + "…"
+}
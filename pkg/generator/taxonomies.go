@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"genny/pkg/utils"
+)
+
+// Taxonomy groups pages by the terms of one front matter key (e.g. tags or categories)
+type Taxonomy struct {
+	Name  string          // Front matter key (e.g. "tags")
+	URL   string          // URL of the taxonomy's listing page, relative to the site root
+	Terms []*TaxonomyTerm // Terms sorted by name
+}
+
+// TaxonomyTerm is a single term of a taxonomy and the pages that carry it
+type TaxonomyTerm struct {
+	Name  string  // Term as first written in front matter
+	Slug  string  // URL-safe form of the term
+	URL   string  // URL of the term's page, relative to the site root
+	Pages []*Page // Pages that carry the term
+}
+
+// Term looks up a term by name or slug, returning nil if no page carries it
+func (t *Taxonomy) Term(name string) *TaxonomyTerm {
+	slug := utils.Slugify(name)
+	for _, term := range t.Terms {
+		if term.Slug == slug {
+			return term
+		}
+	}
+	return nil
+}
+
+// BuildTaxonomy collects the terms of a front matter key across pages. The key may hold
+// a single term or a list of terms. Terms are matched by slug, so "Go" and "go" are the
+// same term. dir is the output directory of the taxonomy's pages.
+func BuildTaxonomy(name string, dir string, pages []*Page) *Taxonomy {
+	taxonomy := &Taxonomy{
+		Name: name,
+		URL:  outputURL(filepath.Join(dir, "index.html")),
+	}
+	terms := make(map[string]*TaxonomyTerm)
+
+	for _, page := range pages {
+		// Later pages of a paginated page carry the same front matter - count it once
+		if page.Paginator != nil && page.Paginator.PageNumber > 1 {
+			continue
+		}
+
+		for _, termName := range metaStrings(page.Meta, name) {
+			slug := utils.Slugify(termName)
+			if slug == "" {
+				continue
+			}
+
+			term, exists := terms[slug]
+			if !exists {
+				term = &TaxonomyTerm{
+					Name: termName,
+					Slug: slug,
+					URL:  outputURL(filepath.Join(dir, slug, "index.html")),
+				}
+				terms[slug] = term
+				taxonomy.Terms = append(taxonomy.Terms, term)
+			}
+			term.Pages = append(term.Pages, page)
+		}
+	}
+
+	sort.Slice(taxonomy.Terms, func(i, j int) bool {
+		return taxonomy.Terms[i].Slug < taxonomy.Terms[j].Slug
+	})
+
+	return taxonomy
+}
+
+// TaxonomyPages generates a taxonomy's index pages from its page templates: a listing
+// page at <dir>/index.html with the taxonomy as .Item, and a page per term at
+// <dir>/<term>/index.html with the term as .Item. Either template may be nil.
+func TaxonomyPages(taxonomy *Taxonomy, dir string, listTemplate, termTemplate *Page) []*Page {
+	var pages []*Page
+
+	if listTemplate != nil {
+		listPage := *listTemplate
+		listPage.OutputPath = filepath.Join(dir, "index.html")
+		listPage.DataContext = taxonomy
+		pages = append(pages, &listPage)
+	}
+
+	if termTemplate != nil {
+		for _, term := range taxonomy.Terms {
+			termPage := *termTemplate
+			termPage.OutputPath = filepath.Join(dir, term.Slug, "index.html")
+			termPage.DataContext = term
+			pages = append(pages, &termPage)
+		}
+	}
+
+	return pages
+}
+
+// metaStrings reads a front matter value as a list of strings, accepting a single value
+// or a list of values
+func metaStrings(meta map[string]interface{}, key string) []string {
+	switch value := meta[key].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		if s := strings.TrimSpace(fmt.Sprint(value)); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+}
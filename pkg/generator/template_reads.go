@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"text/template/parse"
+)
+
+// templateReads works out which data paths a page's template set reads, by walking
+// the parse trees from the page template down through the templates it calls. It tracks
+// whether dot and $ hold the root data, so .projects.items counts at the top of a page but
+// not inside {{ range }} or in a component given a data path. Any use of the root data
+// whose reads can't be followed (printing or piping dot, {{ range . }}, assigning dot to
+// a variable, .Site.Data) is recorded as unknown.
+type templateReads struct {
+	set        *template.Template
+	components map[string]*Component
+	paths      []string        // Data paths read from the root data, as far as they are known
+	unknown    string          // First construct that could read any namespace
+	walked     map[string]bool // Templates already walked, by name and scope
+}
+
+// readScope says which of dot and $ hold the root data
+type readScope struct {
+	dot    bool
+	dollar bool
+}
+
+// pageReads parses a page with its components and returns the data paths it reads from
+// the root data (.projects.items, or projects for {{ index . "projects" }}) and, when it uses the root data in a way that can't be followed, a description of how.
+// The header and footer are left out: they are shared with every page, so anything they
+// read is published anyway.
+func pageReads(site *Site, page *Page) ([]string, string, error) {
+	set := NewTemplate(page.OutputPath, false).Funcs(PageFuncs(site, page, false))
+	if _, err := set.Parse(page.Content); err != nil {
+		return nil, "", &TemplateParseError{Name: page.OutputPath, Source: page.Content, Err: err}
+	}
+	for name, component := range site.Components {
+		if _, err := set.New(name).Parse(component.Template); err != nil {
+			return nil, "", &TemplateParseError{Name: name, Source: component.Template, Err: err}
+		}
+	}
+
+	r := &templateReads{set: set, components: site.Components, walked: make(map[string]bool)}
+	r.walkTemplate(page.OutputPath, readScope{dot: true, dollar: true})
+	return r.paths, r.unknown, nil
+}
+
+// walkTemplate walks a named template of the set, once per scope
+func (r *templateReads) walkTemplate(name string, scope readScope) {
+	key := fmt.Sprintf("%s/%t/%t", name, scope.dot, scope.dollar)
+	if r.walked[key] {
+		return
+	}
+	r.walked[key] = true
+
+	if component, ok := r.components[name]; ok && component.DataPath != "" {
+		r.add(component.DataPath)
+	}
+	if t := r.set.Lookup(name); t != nil && t.Tree != nil {
+		r.walk(t.Tree.Root, scope)
+	}
+}
+
+// walk records the reads of a node and its children
+func (r *templateReads) walk(node parse.Node, scope readScope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			r.walk(child, scope)
+		}
+
+	case *parse.ActionNode:
+		r.walkPipe(n.Pipe, scope)
+
+	case *parse.IfNode:
+		r.walkPipe(n.Pipe, scope)
+		r.walk(n.List, scope)
+		r.walk(n.ElseList, scope)
+
+	case *parse.RangeNode:
+		r.walkPipe(n.Pipe, scope)
+		r.walk(n.List, readScope{dot: false, dollar: scope.dollar})
+		r.walk(n.ElseList, scope)
+
+	case *parse.WithNode:
+		inner := readScope{dot: false, dollar: scope.dollar}
+		if r.isRoot(singleArg(n.Pipe), scope) && len(n.Pipe.Decl) == 0 {
+			// {{ with . }} keeps the root data as dot
+			inner.dot = true
+		} else {
+			r.walkPipe(n.Pipe, scope)
+		}
+		r.walk(n.List, inner)
+		r.walk(n.ElseList, scope)
+
+	case *parse.TemplateNode:
+		if n.Name == "header.html" || n.Name == "footer.html" {
+			return
+		}
+		arg := singleArg(n.Pipe)
+		switch {
+		case n.Pipe == nil:
+			r.walkTemplate(n.Name, readScope{})
+		case r.isRoot(arg, scope) && len(n.Pipe.Decl) == 0:
+			r.walkTemplate(n.Name, readScope{dot: true, dollar: true})
+		default:
+			r.walkPipe(n.Pipe, scope)
+			r.walkTemplate(n.Name, readScope{})
+		}
+	}
+}
+
+// walkPipe records the reads of a pipeline's commands
+func (r *templateReads) walkPipe(pipe *parse.PipeNode, scope readScope) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		args := cmd.Args
+		// index . "ns" reads the namespace named by the string
+		if len(args) >= 2 && isIdentifier(args[0], "index") && r.isRoot(args[1], scope) {
+			if len(args) >= 3 {
+				if key, ok := args[2].(*parse.StringNode); ok {
+					r.add(key.Text)
+					args = args[3:]
+					for _, arg := range args {
+						r.walkArg(arg, scope)
+					}
+					continue
+				}
+			}
+			r.markUnknown(cmd, "index of the root data with a computed key")
+			continue
+		}
+		for _, arg := range args {
+			r.walkArg(arg, scope)
+		}
+	}
+}
+
+// walkArg records the reads of one command argument
+func (r *templateReads) walkArg(arg parse.Node, scope readScope) {
+	switch a := arg.(type) {
+	case *parse.DotNode:
+		if scope.dot {
+			r.markUnknown(a, "the root data passed as a value")
+		}
+	case *parse.FieldNode:
+		if scope.dot {
+			r.addFields(a, a.Ident)
+		}
+	case *parse.VariableNode:
+		if a.Ident[0] == "$" && scope.dollar {
+			if len(a.Ident) == 1 {
+				r.markUnknown(a, "the root data passed as a value")
+				return
+			}
+			r.addFields(a, a.Ident[1:])
+		}
+	case *parse.ChainNode:
+		if r.isRoot(a.Node, scope) {
+			r.markUnknown(a, "a field of the root data read through a chain")
+			return
+		}
+		r.walkArg(a.Node, scope)
+	case *parse.PipeNode:
+		r.walkPipe(a, scope)
+	}
+}
+
+// addFields records a data path read from the root data. .Site.Data
+// and .Site alone give access to every namespace; the other reserved keys hold no data
+// namespaces.
+func (r *templateReads) addFields(node parse.Node, fields []string) {
+	switch fields[0] {
+	case "Site":
+		if len(fields) == 1 || fields[1] == "Data" {
+			r.markUnknown(node, "the site data read through .Site")
+		}
+	case "Page", "Item", "Paginator", "Env", "Build":
+	default:
+		r.add(strings.Join(fields, "."))
+	}
+}
+
+// isRoot reports whether a node is dot or $ holding the root data
+func (r *templateReads) isRoot(node parse.Node, scope readScope) bool {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return scope.dot
+	case *parse.VariableNode:
+		return scope.dollar && len(n.Ident) == 1 && n.Ident[0] == "$"
+	}
+	return false
+}
+
+// add records a data path read from the root data
+func (r *templateReads) add(path string) {
+	if path = strings.TrimPrefix(path, "."); path != "" {
+		r.paths = append(r.paths, path)
+	}
+}
+
+// markUnknown records the first construct whose reads can't be followed
+func (r *templateReads) markUnknown(node parse.Node, reason string) {
+	if r.unknown == "" {
+		r.unknown = fmt.Sprintf("%s ({{ %s }})", reason, node)
+	}
+}
+
+// singleArg returns the only argument of a pipeline with a single command, or nil
+func singleArg(pipe *parse.PipeNode) parse.Node {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	return pipe.Cmds[0].Args[0]
+}
+
+// isIdentifier reports whether a node is the named function
+func isIdentifier(node parse.Node, name string) bool {
+	ident, ok := node.(*parse.IdentifierNode)
+	return ok && ident.Ident == name
+}
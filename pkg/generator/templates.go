@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// missingKeyPattern matches the error html/template reports for a missing map key in strict mode
+var missingKeyPattern = regexp.MustCompile(`executing "([^"]+)" at <([^>]+)>: map has no entry for key "([^"]+)"`)
+
+// NewTemplate creates an empty template set with the standard helper functions. In strict
+// mode, executing a template that reads a missing map key fails instead of printing nothing.
+func NewTemplate(name string, strict bool) *template.Template {
+	t := template.New(name).Funcs(templateFuncs())
+	if strict {
+		t.Option("missingkey=error")
+	}
+	return t
+}
+
+// parsePageTemplate creates the template set for a page or the main index (page is nil):
+// the page content itself plus all components, header and footer
+func parsePageTemplate(name, content string, site *Site, page *Page, preview bool, headerContent, footerContent string, strict bool) (*template.Template, error) {
+	t := NewTemplate(name, strict).Funcs(PageFuncs(site, page, preview))
+
+	// Parse the page content first
+	if _, err := t.Parse(content); err != nil {
+		return nil, &TemplateParseError{Name: name, Source: content, Err: err}
+	}
+
+	// Add components
+	for compName, comp := range site.Components {
+		if _, err := t.New(compName).Parse(comp.Template); err != nil {
+			return nil, &TemplateParseError{Name: compName, Source: comp.Template, Err: err}
+		}
+	}
+
+	// Add header and footer if they exist
+	if headerContent != "" {
+		if _, err := t.New("header.html").Parse(headerContent); err != nil {
+			return nil, &TemplateParseError{Name: "header.html", Source: headerContent, Err: err}
+		}
+	}
+
+	if footerContent != "" {
+		if _, err := t.New("footer.html").Parse(footerContent); err != nil {
+			return nil, &TemplateParseError{Name: "footer.html", Source: footerContent, Err: err}
+		}
+	}
+
+	return t, nil
+}
+
+// executeError wraps a template execution error for the named page. In strict mode, a
+// missing map key is reported as a MissingDataError naming the template that read it.
+func executeError(page string, err error) error {
+	match := missingKeyPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return &TemplateExecuteError{Name: page, Err: err}
+	}
+
+	missing := &MissingDataError{Page: page, Template: match[1], Path: match[2], Err: err}
+	if !isPageTemplateName(match[1]) {
+		missing.Component = match[1]
+	}
+	return missing
+}
+
+// isPageTemplateName reports whether a template name belongs to the page-level templates
+// (page content, header, footer, wrapper) rather than a component
+func isPageTemplateName(name string) bool {
+	switch name {
+	case "Main", "Page", "Wrapper", "components":
+		return true
+	}
+	return strings.HasSuffix(name, ".html") || strings.HasSuffix(name, OutputTemplateExt)
+}
@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"genny/pkg/utils"
+)
+
+// TOCEntry is a heading in a page's table of contents. Entries nest by heading level, so
+// a component passed to {{ toc "toc" }} can render the tree recursively:
+//
+//	<ul>{{ range . }}<li><a href="#{{ .ID }}">{{ .Text }}</a>{{ if .Children }}{{ template "toc" .Children }}{{ end }}</li>{{ end }}</ul>
+type TOCEntry struct {
+	Level    int         // Heading level (2 to 4)
+	Text     string      // Heading text without markup
+	ID       string      // Anchor id of the heading
+	Children []*TOCEntry // Lower level headings up to the next heading of this level
+}
+
+var (
+	// headingPattern matches the h2–h4 headings of rendered HTML
+	headingPattern = regexp.MustCompile(`(?is)<h([2-4])(\s[^>]*)?>(.*?)</h[2-4]\s*>`)
+	// headingIDPattern matches an existing id attribute of a heading
+	headingIDPattern = regexp.MustCompile(`(?i)\sid\s*=\s*"([^"]*)"`)
+	// tagPattern matches any markup inside a heading
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+	// tocMarkerPattern matches the marks left by the toc function
+	tocMarkerPattern = regexp.MustCompile(`<!--genny-toc:([^>]*)-->`)
+)
+
+// tocMarker marks a table of contents in rendered HTML until the headings are known. A
+// comment, since html/template only strips the comments written in template text.
+const tocMarker = "<!--genny-toc:%s-->"
+
+// addHeadingAnchors gives every h2–h4 heading in rendered HTML an id and returns the
+// headings as a tree. Ids are slugs of the heading text, numbered when repeated
+// ("setup", "setup-2"); headings that already have an id keep it.
+func addHeadingAnchors(content string) (string, []*TOCEntry) {
+	used := make(map[string]bool)
+	for _, match := range headingPattern.FindAllStringSubmatch(content, -1) {
+		if id := headingIDPattern.FindStringSubmatch(match[2]); id != nil {
+			used[id[1]] = true
+		}
+	}
+
+	var headings []*TOCEntry
+	result := headingPattern.ReplaceAllStringFunc(content, func(heading string) string {
+		match := headingPattern.FindStringSubmatch(heading)
+		level := int(match[1][0] - '0')
+		text := headingText(match[3])
+
+		if id := headingIDPattern.FindStringSubmatch(match[2]); id != nil {
+			headings = append(headings, &TOCEntry{Level: level, Text: text, ID: id[1]})
+			return heading
+		}
+
+		id := uniqueID(utils.Slugify(text), used)
+		headings = append(headings, &TOCEntry{Level: level, Text: text, ID: id})
+		return fmt.Sprintf(`<h%d id="%s"%s>%s</h%d>`, level, id, match[2], match[3], level)
+	})
+
+	return result, nestHeadings(headings)
+}
+
+// headingText returns the plain text of a heading's inner HTML
+func headingText(inner string) string {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(inner, ""))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// uniqueID returns slug, or slug-2, slug-3... when it is already used, and marks it used
+func uniqueID(slug string, used map[string]bool) string {
+	if slug == "" {
+		slug = "section"
+	}
+	id := slug
+	for n := 2; used[id]; n++ {
+		id = fmt.Sprintf("%s-%d", slug, n)
+	}
+	used[id] = true
+	return id
+}
+
+// nestHeadings builds the heading tree: each heading becomes a child of the closest
+// preceding heading of a higher level
+func nestHeadings(headings []*TOCEntry) []*TOCEntry {
+	var roots []*TOCEntry
+	var stack []*TOCEntry
+
+	for _, heading := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].Level >= heading.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, heading)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, heading)
+		}
+		stack = append(stack, heading)
+	}
+
+	return roots
+}
+
+// tocFunc is the toc template function. It marks where the page's table of contents goes:
+// {{ toc }} renders it as nested lists, {{ toc "toc" }} with a component given the
+// entries. The headings are only known once the page is rendered, so the mark is
+// replaced afterwards by injectTOC.
+func tocFunc(component ...string) (template.HTML, error) {
+	if len(component) > 1 {
+		return "", fmt.Errorf("toc: expected at most one component name, got %d", len(component))
+	}
+	name := ""
+	if len(component) == 1 {
+		if name = component[0]; name == "" {
+			return "", fmt.Errorf("toc: component name is empty")
+		}
+	}
+	return template.HTML(fmt.Sprintf(tocMarker, name)), nil
+}
+
+// injectTOC replaces the toc marks in a rendered page with its table of contents,
+// rendered by the named component of the page's template set or as nested lists
+func injectTOC(content string, t *template.Template, toc []*TOCEntry) (string, error) {
+	var injectErr error
+	result := tocMarkerPattern.ReplaceAllStringFunc(content, func(marker string) string {
+		name := tocMarkerPattern.FindStringSubmatch(marker)[1]
+		if name == "" {
+			return tocList(toc)
+		}
+
+		component := t.Lookup(name)
+		if component == nil {
+			if injectErr == nil {
+				injectErr = &ComponentNotFoundError{Name: name}
+			}
+			return ""
+		}
+		var buf bytes.Buffer
+		if err := component.Execute(&buf, toc); err != nil {
+			if injectErr == nil {
+				injectErr = executeError(name, err)
+			}
+			return ""
+		}
+		return buf.String()
+	})
+	return result, injectErr
+}
+
+// tocList renders a table of contents as nested lists of links, the outer one with the
+// "toc" class, or nothing when the page has no headings
+func tocList(entries []*TOCEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	writeTOCList(&b, entries, ` class="toc"`)
+	return b.String()
+}
+
+// writeTOCList writes one level of a table of contents and, nested, the levels below it
+func writeTOCList(b *strings.Builder, entries []*TOCEntry, attributes string) {
+	fmt.Fprintf(b, "<ul%s>", attributes)
+	for _, entry := range entries {
+		fmt.Fprintf(b, `<li><a href="#%s">%s</a>`, html.EscapeString(entry.ID), html.EscapeString(entry.Text))
+		if len(entry.Children) > 0 {
+			writeTOCList(b, entry.Children, "")
+		}
+		b.WriteString("</li>")
+	}
+	b.WriteString("</ul>")
+}
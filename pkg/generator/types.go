@@ -3,16 +3,54 @@
 // generators for creating component previews and main site pages.
 package generator
 
-import "html/template"
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"time"
+)
 
 // Site represents the entire static site project with all its resources
 type Site struct {
-	RootPath   string
-	Assets     []Asset
-	Data       DataContext
-	Components map[string]*Component
-	Pages      []*Page
-	Templates  map[string]*template.Template
+	RootPath     string
+	Assets       []Asset
+	Data         DataContext
+	Components   map[string]*Component
+	Pages        []*Page
+	Templates    map[string]*template.Template
+	Taxonomies   map[string]*Taxonomy    // Taxonomies configured in genny.yaml, by name
+	HeldPages    []*Page                 // Drafts and scheduled pages, generated as previews only
+	Outputs      []*OutputTemplate       // Non-page output templates (*.tmpl)
+	Menu         []*MenuItem             // Page tree built from output paths
+	Environment  string                  // Active environment (e.g. "production"), empty if none was set
+	Env          map[string]string       // Allowlisted environment variables, exposed as .Env
+	Build        *BuildInfo              // Details of the current build, exposed as .Build
+	DataHistory  map[string]*FileHistory // Change history of each data file, by root-relative path
+	DataLinks    map[string][]string     // Namespace (or derived collection) → namespaces its data was copied from
+	Highlight    *HighlightOptions       // Build-time syntax highlighting, nil when disabled
+	SummaryWords int                     // Length of summaries taken from page text (0 uses the default)
+	API          *APIOptions             // JSON export of data namespaces, nil when disabled
+	BaseURL      string                  // Absolute URL of the deployed site, for the sitemap
+	IndexHistory *FileHistory            // Change history of index.html
+
+	pageIndex    map[string][]*Page // Pages by id and source name, built on first use by pageURL
+	dependencies map[string]bool    // Extra files read while generating (see Dependencies)
+}
+
+// BuildInfo describes the build that generated the site
+type BuildInfo struct {
+	Commit      string    // Full git commit hash, empty outside a git repository
+	ShortCommit string    // First 7 characters of Commit
+	Branch      string    // Git branch, empty outside a repository or on a detached HEAD
+	Time        time.Time // When the build started
+	Version     string    // genny version
+}
+
+// AllPages returns the published pages followed by the held back ones
+func (s *Site) AllPages() []*Page {
+	pages := make([]*Page, 0, len(s.Pages)+len(s.HeldPages))
+	pages = append(pages, s.Pages...)
+	return append(pages, s.HeldPages...)
 }
 
 // Component represents a reusable HTML component with its template and data requirements
@@ -21,7 +59,8 @@ type Component struct {
 	FilePath     string
 	Template     string
 	DataPath     string
-	Dependencies []string // Names of other components this component references
+	Dependencies []string     // Names of other components this component references
+	History      *FileHistory // Change history of the component file
 }
 
 // Page represents a single output HTML page
@@ -30,15 +69,70 @@ type Page struct {
 	OutputPath  string      // Output file path (relative to www/)
 	Content     string      // Raw HTML content
 	Template    string      // Processed template content
-	DataContext interface{} // Data for template execution
+	DataContext interface{} // Data for template execution (the bound item for collection pages)
 	IsPreview   bool        // True for component previews, false for main site pages
 	EncryptKey  string      // If set, the page output will be encrypted with this passphrase
+
+	Meta        map[string]interface{} // Front matter metadata
+	Paginator   *Paginator             // Set for pages generated from a paginated collection
+	Breadcrumbs []*MenuItem            // Chain from the site root to this page
+	History     *FileHistory           // Change history of the source file
+	TOC         []*TOCEntry            // Tree of the page's h2–h4 headings (see ScanPages)
+	Summary     string                 // Short plain text description of the page (see ScanPages)
+
+	headings []*TOCEntry // The heading tree, kept here for encrypted pages, whose TOC isn't shared
+}
+
+// OutputTemplate is a template for a non-page output file (a feed, manifest.json,
+// humans.txt...), written without header and footer
+type OutputTemplate struct {
+	SourcePath string // Source file path (e.g. "feeds/events.xml.tmpl")
+	OutputPath string // Output file path relative to www/ (e.g. "feeds/events.xml")
+	Content    string // Template content
+}
+
+// FileHistory is a file's change history, read from git or, without it, the file's
+// modification time
+type FileHistory struct {
+	Updated time.Time // Date of the last commit touching the file
+	Author  string    // Author of the last commit, empty without git
+	Created time.Time // Date of the first commit adding the file
+	FromGit bool      // False when the dates are the file's modification time
+}
+
+// URL returns the page's URL relative to the site root (e.g. "about.html", "news/page/2/")
+func (p *Page) URL() string {
+	return outputURL(p.OutputPath)
+}
+
+// Title returns the page title: the bound item's title for collection and taxonomy pages,
+// the front matter title, or a title derived from the output path
+func (p *Page) Title() string {
+	switch item := p.DataContext.(type) {
+	case map[string]interface{}:
+		if title, exists := item["title"]; exists {
+			return fmt.Sprint(title)
+		}
+	case *TaxonomyTerm:
+		return item.Name
+	}
+
+	if title, exists := p.Meta["title"]; exists {
+		return fmt.Sprint(title)
+	}
+
+	key := menuKey(p.URL())
+	if key == "" {
+		return homeTitle
+	}
+	return titleFromSegment(path.Base(key))
 }
 
 // Asset represents a static asset file (image, font, etc.)
 type Asset struct {
-	SourcePath string
-	OutputPath string
+	SourcePath      string
+	OutputPath      string
+	FingerprintPath string // Content-hashed output path, set once a template asks for it
 }
 
 // DataContext provides type-safe access to YAML data
@@ -66,32 +160,15 @@ func NewSimpleDataContext(data map[string]interface{}) *SimpleDataContext {
 	return &SimpleDataContext{data: data}
 }
 
-// Get retrieves data at the given dot-separated path
+// Get retrieves data at the given path. Segments are map keys or list indexes
+// (".projects.items.0", ".news.-1" for the last item), and lists also take selectors
+// (".team[name=alice]", ".items[0]").
 func (ctx *SimpleDataContext) Get(path string) (interface{}, error) {
 	if path == "" || path == "." {
 		return ctx.data, nil
 	}
 
-	parts := splitPath(path)
-	var current interface{} = ctx.data
-
-	for _, part := range parts {
-		if part == "" {
-			continue
-		}
-
-		currentMap, ok := current.(map[string]interface{})
-		if !ok {
-			return nil, &DataPathError{Path: path, Part: part}
-		}
-
-		current, ok = currentMap[part]
-		if !ok {
-			return nil, &DataPathError{Path: path, Part: part}
-		}
-	}
-
-	return current, nil
+	return LookupPath(ctx.data, path)
 }
 
 // GetAll returns all loaded data
@@ -99,63 +176,54 @@ func (ctx *SimpleDataContext) GetAll() map[string]interface{} {
 	return ctx.data
 }
 
-// Set stores data at the given path
+// Set stores data at the given path, creating missing maps along the way. List
+// elements can be replaced using the same indexes and selectors as Get.
 func (ctx *SimpleDataContext) Set(path string, value interface{}) error {
 	if path == "" || path == "." {
 		if m, ok := value.(map[string]interface{}); ok {
 			ctx.data = m
 			return nil
 		}
-		return &DataPathError{Path: path, Part: "root"}
+		return &DataPathError{Path: path, Part: "root", Found: describeValue(value), Reason: "the root must be a map"}
 	}
 
-	parts := splitPath(path)
-	current := ctx.data
-
-	for i, part := range parts[:len(parts)-1] {
-		if part == "" {
-			continue
-		}
-
-		if _, exists := current[part]; !exists {
-			current[part] = make(map[string]interface{})
-		}
-
-		nextMap, ok := current[part].(map[string]interface{})
-		if !ok {
-			return &DataPathError{Path: path, Part: parts[i]}
-		}
-		current = nextMap
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
 	}
-
-	lastPart := parts[len(parts)-1]
-	current[lastPart] = value
-	return nil
-}
-
-// splitPath splits a dot-separated path into parts
-func splitPath(path string) []string {
-	if path == "" || path == "." {
-		return []string{}
+	if len(segments) == 0 {
+		return &DataPathError{Path: path, Part: path, Reason: "the path names no key"}
 	}
 
-	var parts []string
-	current := ""
-
-	for _, char := range path {
-		if char == '.' {
-			if current != "" {
-				parts = append(parts, current)
-				current = ""
+	var current interface{} = ctx.data
+	for _, segment := range segments[:len(segments)-1] {
+		// Missing map keys are created rather than reported
+		if m, ok := current.(map[string]interface{}); ok && !segment.Selector {
+			if _, exists := m[segment.Key]; !exists {
+				m[segment.Key] = make(map[string]interface{})
 			}
-		} else {
-			current += string(char)
+		}
+		current, err = resolveSegment(path, current, segment)
+		if err != nil {
+			return err
 		}
 	}
 
-	if current != "" {
-		parts = append(parts, current)
+	last := segments[len(segments)-1]
+	switch parent := current.(type) {
+	case map[string]interface{}:
+		if last.Selector {
+			return &DataPathError{Path: path, Part: last.Text, Found: describeValue(current), Reason: "selectors only apply to lists"}
+		}
+		parent[last.Key] = value
+	case []interface{}:
+		index, err := listIndex(path, parent, last)
+		if err != nil {
+			return err
+		}
+		parent[index] = value
+	default:
+		return &DataPathError{Path: path, Part: last.Text, Found: describeValue(current), Reason: "only maps and lists can hold values"}
 	}
-
-	return parts
+	return nil
 }
@@ -0,0 +1,26 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"genny/pkg/config"
+
+	"github.com/toolvox/utilgo/pkg/serialization/yaml"
+)
+
+// LoadConfig loads the project configuration from genny.yaml, falling back to defaults
+func (l *FileSystemLoader) LoadConfig(root string) (*config.Config, error) {
+	configPath := filepath.Join(root, config.FileName)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return config.Default(), nil
+	}
+
+	cfg := config.Default()
+	if err := yaml.UnmarshalFileInto(configPath, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+
+	return cfg, nil
+}
@@ -6,13 +6,59 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/toolvox/utilgo/pkg/serialization/yaml"
+	"genny/pkg/generator"
+
+	"github.com/BurntSushi/toml"
+	"github.com/toolvox/utilgo/pkg/serialization/json"
 )
 
-// LoadData loads and merges all YAML data files from the data directory
-func (l *FileSystemLoader) LoadData(root string) (map[string]interface{}, error) {
+// dataDecoder decodes a data file; dataDir is the data directory, which confines the
+// files a YAML file can include
+type dataDecoder func(path, dataDir string) (interface{}, error)
+
+// dataDecoders maps data file extensions to the functions that decode them
+var dataDecoders = map[string]dataDecoder{
+	".yaml": decodeYAMLFile,
+	".yml":  decodeYAMLFile,
+	".json": withoutIncludes(decodeJSONFile),
+	".toml": withoutIncludes(decodeTOMLFile),
+	".csv":  withoutIncludes(decodeCSVFile),
+}
+
+// withoutIncludes adapts the decoder of a format that can't include other files
+func withoutIncludes(decode func(path string) (interface{}, error)) dataDecoder {
+	return func(path, _ string) (interface{}, error) {
+		return decode(path)
+	}
+}
+
+// dataFile is a loaded data file and the namespace it was placed at, kept to validate
+// the namespace's final data against the file's schema
+type dataFile struct {
+	Path      string
+	Namespace []string
+}
+
+// LoadData loads and merges all data files (YAML, JSON, TOML and CSV) from the data directory.
+// Each file is namespaced by its path: data/projects.yaml is "projects" and
+// data/blog/authors.yaml is "blog.authors". A file and a directory with the same name
+// (data/blog.yaml and data/blog/) are merged. Two sources defining the same key is an error.
+// YAML files can pull in other files of the data directory with !include (included
+// files starting with "_" are only loaded that way), and {$ref: path} values are
+// replaced once all files are merged. Overlay files for the active environment
+// (data/site.production.yaml) are deep-merged over the base data; overlays for other
+// environments are ignored. Files with a JSON Schema (a sibling *.schema.json, or one
+// mapped to their namespace in schemas) are validated last, against their data with
+// references resolved and overlays applied, and all violations are reported together
+// as a DataSchemaError. The returned sources list the applied overlays and, for each
+// namespace with references, the namespaces they point into.
+func (l *FileSystemLoader) LoadData(root string, options DataOptions) (map[string]interface{}, *DataSources, error) {
 	dataPath := filepath.Join(root, "data")
 	result := make(map[string]interface{})
+	sources := make(map[string]string)
+	included := includeTargets(dataPath)
+	var files []dataFile
+	var overlays []dataOverlay
 
 	err := filepath.WalkDir(dataPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -22,24 +68,209 @@ func (l *FileSystemLoader) LoadData(root string) (map[string]interface{}, error)
 		if d.IsDir() {
 			return nil
 		}
-		if !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
+
+		decode, ok := dataDecoders[strings.ToLower(filepath.Ext(path))]
+		if !ok || strings.HasSuffix(strings.ToLower(path), schemaSuffix) {
 			return nil
 		}
 
-		data, err := yaml.UnmarshalFile[map[string]interface{}](path)
+		relPath, err := filepath.Rel(dataPath, path)
 		if err != nil {
-			return fmt.Errorf("failed to parse YAML file %s: %w", path, err)
+			return fmt.Errorf("failed to get relative path for %s: %w", path, err)
 		}
+		namespace := strings.Split(filepath.ToSlash(strings.TrimSuffix(relPath, filepath.Ext(relPath))), "/")
 
-		filename := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
-		result[filename] = data
+		// Fragments pulled in with !include aren't namespaces of their own
+		if isFragment(namespace) {
+			if absPath, err := filepath.Abs(path); err == nil && included[absPath] {
+				return nil
+			}
+		}
 
-		return nil
+		// Overlays are applied once all base data is loaded
+		if base, env, isOverlay := overlayNamespace(namespace, options); isOverlay {
+			if env == options.Env {
+				overlays = append(overlays, dataOverlay{Path: path, Namespace: base, Decode: func(path string) (interface{}, error) {
+					return decode(path, dataPath)
+				}})
+			}
+			return nil
+		}
+
+		data, err := decode(path, dataPath)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, dataFile{Path: path, Namespace: namespace})
+		return insertData(result, namespace, data, path, sources)
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk data directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to walk data directory: %w", err)
+	}
+
+	var applied []string
+	for _, overlay := range overlays {
+		// An overlay without a base file is validated as the namespace's file
+		if _, exists := sources[strings.Join(overlay.Namespace, ".")]; !exists {
+			files = append(files, dataFile{Path: overlay.Path, Namespace: overlay.Namespace})
+		}
+		if err := applyOverlay(result, overlay, sources); err != nil {
+			return nil, nil, err
+		}
+		applied = append(applied, overlay.Path)
+	}
+
+	links, err := resolveReferences(result, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	validator := newSchemaValidator(root, options.Schemas)
+	var violations []generator.SchemaViolation
+	for _, file := range files {
+		fileViolations, err := validator.Validate(file.Path, file.Namespace, fileData(result, file, sources))
+		if err != nil {
+			return nil, nil, err
+		}
+		violations = append(violations, fileViolations...)
+	}
+	if len(violations) > 0 {
+		return nil, nil, &generator.DataSchemaError{Violations: violations}
+	}
+
+	return result, &DataSources{Overlays: applied, Links: links}, nil
+}
+
+// isFragment reports whether a data file or one of its directories is named like an
+// include fragment, starting with "_"
+func isFragment(namespace []string) bool {
+	for _, segment := range namespace {
+		if strings.HasPrefix(segment, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+// fileData returns the data a file's schema applies to: the final value at its namespace,
+// without the keys that files of a data directory with the same name merged in
+func fileData(result map[string]interface{}, file dataFile, sources map[string]string) interface{} {
+	var value interface{} = result
+	for _, segment := range file.Namespace {
+		parent, _ := value.(map[string]interface{})
+		value = parent[segment]
+	}
+
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	key := strings.Join(file.Namespace, ".")
+	own := make(map[string]interface{}, len(data))
+	for childKey, child := range data {
+		if source, ok := sources[key+"."+childKey]; ok && source != file.Path {
+			continue
+		}
+		own[childKey] = child
+	}
+	return own
+}
+
+// insertData places a data file's content at its namespace, creating parent namespaces
+// for directories and merging a file with the directory of the same name. sources records
+// which file defined each dotted key, for error messages.
+func insertData(result map[string]interface{}, namespace []string, data interface{}, path string, sources map[string]string) error {
+	parent := result
+	for i, segment := range namespace[:len(namespace)-1] {
+		key := strings.Join(namespace[:i+1], ".")
+		existing, exists := parent[segment]
+		if !exists {
+			child := make(map[string]interface{})
+			parent[segment] = child
+			parent = child
+			continue
+		}
+
+		child, ok := existing.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("data namespace '%s' from %s is not a map, so it can't also hold %s", key, sources[key], path)
+		}
+		parent = child
+	}
+
+	name := namespace[len(namespace)-1]
+	key := strings.Join(namespace, ".")
+	existing, exists := parent[name]
+	if !exists {
+		parent[name] = data
+		sources[key] = path
+		return nil
+	}
+
+	// Only a map from a file can merge with a directory of the same name
+	existingMap, isDir := existing.(map[string]interface{})
+	if source, fromFile := sources[key]; fromFile || !isDir {
+		return fmt.Errorf("data namespace '%s' is claimed by both %s and %s", key, source, path)
+	}
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data file %s must hold a map to merge with the '%s' data directory", path, key)
+	}
+
+	for childKey, value := range dataMap {
+		fullKey := key + "." + childKey
+		if _, clash := existingMap[childKey]; clash {
+			return fmt.Errorf("data key '%s' is defined by both %s and %s", fullKey, sources[fullKey], path)
+		}
+		existingMap[childKey] = value
+		sources[fullKey] = path
+	}
+	sources[key] = path
+
+	return nil
+}
+
+// decodeJSONFile decodes a JSON data file
+func decodeJSONFile(path string) (interface{}, error) {
+	data, err := json.UnmarshalFile[interface{}](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON file %s: %w", path, err)
 	}
+	return data, nil
+}
+
+// decodeTOMLFile decodes a TOML data file, converting arrays of tables to plain lists
+// so they behave like YAML lists in templates
+func decodeTOMLFile(path string) (interface{}, error) {
+	var data map[string]interface{}
+	if _, err := toml.DecodeFile(path, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse TOML file %s: %w", path, err)
+	}
+	return normalizeTOML(data), nil
+}
 
-	return result, nil
+// normalizeTOML converts the typed slices produced by the TOML decoder to []interface{}
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeTOML(item)
+		}
+		return v
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = normalizeTOML(item)
+		}
+		return list
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+		return v
+	default:
+		return v
+	}
 }
@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// csvColumn is a CSV header cell, optionally typed with a ":type" suffix (e.g. "price:float")
+type csvColumn struct {
+	Name string
+	Type string
+}
+
+// decodeCSVFile decodes a CSV data file into a list of row maps keyed by the header row.
+// Header cells may declare a type ("age:int", "price:float", "active:bool"); untyped
+// columns stay strings. Empty cells in typed columns become nil.
+func decodeCSVFile(path string) (interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV file %s: %w", path, err)
+	}
+
+	columns, err := parseCSVHeader(header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header in %s: %w", path, err)
+	}
+
+	rows := []interface{}{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV file %s: %w", path, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			value, err := convertCSVValue(record[i], column.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: column '%s': %w", path, line, column.Name, err)
+			}
+			row[column.Name] = value
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseCSVHeader splits header cells into column names and types
+func parseCSVHeader(header []string) ([]csvColumn, error) {
+	columns := make([]csvColumn, len(header))
+	seen := make(map[string]bool)
+
+	for i, cell := range header {
+		name, typ, _ := strings.Cut(strings.TrimSpace(cell), ":")
+		name = strings.TrimSpace(name)
+		typ = strings.ToLower(strings.TrimSpace(typ))
+
+		if name == "" {
+			return nil, fmt.Errorf("column %d has no name", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate column '%s'", name)
+		}
+		switch typ {
+		case "", "string", "int", "float", "bool":
+		default:
+			return nil, fmt.Errorf("column '%s' has unknown type '%s' (expected string, int, float or bool)", name, typ)
+		}
+
+		seen[name] = true
+		columns[i] = csvColumn{Name: name, Type: typ}
+	}
+
+	return columns, nil
+}
+
+// convertCSVValue converts a CSV cell to its column's type
+func convertCSVValue(cell string, typ string) (interface{}, error) {
+	if typ == "" || typ == "string" {
+		return cell, nil
+	}
+
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		return nil, nil
+	}
+
+	switch typ {
+	case "int":
+		return strconv.Atoi(cell)
+	case "float":
+		return strconv.ParseFloat(cell, 64)
+	case "bool":
+		return strconv.ParseBool(cell)
+	}
+	return cell, nil
+}
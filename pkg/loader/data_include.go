@@ -0,0 +1,176 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"genny/pkg/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeTag is the YAML tag that replaces a value with the content of another data file
+const includeTag = "!include"
+
+// decodeYAMLFile decodes a YAML data file, which must hold a map. Included files must be
+// inside dataDir.
+func decodeYAMLFile(path, dataDir string) (interface{}, error) {
+	var data map[string]interface{}
+	if err := decodeYAMLWithIncludes(path, &data, dataDir, nil); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodeYAMLWithIncludes decodes a YAML file into out, first replacing every
+// "!include other.yaml" value with the decoded content of that file. Include paths are
+// relative to the including file and must stay inside dataDir; stack holds the files
+// being included, to catch cycles.
+func decodeYAMLWithIncludes(path string, out interface{}, dataDir string, stack []string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve data file %s: %w", path, err)
+	}
+	for _, including := range stack {
+		if including == absPath {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(stack, absPath), " -> "))
+		}
+	}
+	stack = append(stack, absPath)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read YAML file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse YAML file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	if err := expandIncludes(&doc, path, dataDir, stack); err != nil {
+		return err
+	}
+
+	if err := doc.Decode(out); err != nil {
+		return fmt.Errorf("failed to parse YAML file %s: %w", path, err)
+	}
+	return nil
+}
+
+// expandIncludes replaces !include nodes in a YAML tree with the files they name
+func expandIncludes(node *yaml.Node, path, dataDir string, stack []string) error {
+	if node.Tag == includeTag {
+		if node.Kind != yaml.ScalarNode || node.Value == "" {
+			return fmt.Errorf("%s:%d: %s needs a file path", path, node.Line, includeTag)
+		}
+
+		included, err := includePath(path, node.Value, dataDir)
+		if err != nil {
+			return fmt.Errorf("%s:%d: failed to include %s: %w", path, node.Line, node.Value, err)
+		}
+		value, err := decodeIncludedFile(included, dataDir, stack)
+		if err != nil {
+			return fmt.Errorf("%s:%d: failed to include %s: %w", path, node.Line, node.Value, err)
+		}
+
+		var replacement yaml.Node
+		if err := replacement.Encode(value); err != nil {
+			return fmt.Errorf("%s:%d: failed to include %s: %w", path, node.Line, node.Value, err)
+		}
+		*node = replacement
+		return nil
+	}
+
+	for _, child := range node.Content {
+		if err := expandIncludes(child, path, dataDir, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includePath resolves an include target relative to the including file, rejecting
+// absolute paths and paths (or symlinks) that lead outside the data directory
+func includePath(path, target, dataDir string) (string, error) {
+	if filepath.IsAbs(target) {
+		return "", fmt.Errorf("path must be relative to the including file")
+	}
+
+	included := filepath.Join(filepath.Dir(path), filepath.FromSlash(target))
+	inside, err := utils.WithinDir(dataDir, included)
+	if err != nil {
+		return "", err
+	}
+	if !inside {
+		return "", fmt.Errorf("path is outside the data directory")
+	}
+	return included, nil
+}
+
+// includeTargets returns the absolute paths of the files that the YAML files in the data
+// directory include. Files that fail to parse are skipped here and reported when loaded.
+func includeTargets(dataDir string) map[string]bool {
+	targets := make(map[string]bool)
+	filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var doc yaml.Node
+		if yaml.Unmarshal(content, &doc) != nil {
+			return nil
+		}
+		collectIncludes(&doc, path, targets)
+		return nil
+	})
+	return targets
+}
+
+// collectIncludes adds the absolute paths of a YAML tree's !include targets to targets
+func collectIncludes(node *yaml.Node, path string, targets map[string]bool) {
+	if node.Tag == includeTag && node.Kind == yaml.ScalarNode && node.Value != "" {
+		if included, err := filepath.Abs(filepath.Join(filepath.Dir(path), filepath.FromSlash(node.Value))); err == nil {
+			targets[included] = true
+		}
+		return
+	}
+	for _, child := range node.Content {
+		collectIncludes(child, path, targets)
+	}
+}
+
+// decodeIncludedFile decodes an included file of any data format; YAML files may
+// include further files
+func decodeIncludedFile(path, dataDir string, stack []string) (interface{}, error) {
+	// A switch rather than dataDecoders, which would make an initialization cycle
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var value interface{}
+		if err := decodeYAMLWithIncludes(path, &value, dataDir, stack); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case ".json":
+		return decodeJSONFile(path)
+	case ".toml":
+		return decodeTOMLFile(path)
+	case ".csv":
+		return decodeCSVFile(path)
+	default:
+		return nil, fmt.Errorf("unsupported data file format '%s'", ext)
+	}
+}
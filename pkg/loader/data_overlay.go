@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// dataOverlay is a data file that is merged over the base data in one environment:
+// data/site.production.yaml overlays the "site" namespace when building for production
+type dataOverlay struct {
+	Path      string
+	Namespace []string
+	Decode    func(path string) (interface{}, error)
+}
+
+// knownEnvironments are the environment names overlay files can target without being
+// configured
+var knownEnvironments = []string{"development", "dev", "local", "test", "staging", "preview", "production", "prod"}
+
+// overlayNamespace splits an overlay's environment off its namespace. A file is an
+// overlay when its name has an extra dotted suffix naming an environment
+// (site.production); other dotted names (site.config, v1.2) are plain data files.
+func overlayNamespace(namespace []string, options DataOptions) (base []string, env string, ok bool) {
+	last := namespace[len(namespace)-1]
+	dot := strings.LastIndex(last, ".")
+	if dot <= 0 || dot == len(last)-1 {
+		return namespace, "", false
+	}
+
+	env = last[dot+1:]
+	if env != options.Env && !slices.Contains(knownEnvironments, env) && !slices.Contains(options.Environments, env) {
+		return namespace, "", false
+	}
+
+	base = append(append([]string{}, namespace[:len(namespace)-1]...), last[:dot])
+	return base, env, true
+}
+
+// applyOverlay deep-merges an overlay file's content over the data at its namespace
+func applyOverlay(result map[string]interface{}, overlay dataOverlay, sources map[string]string) error {
+	data, err := overlay.Decode(overlay.Path)
+	if err != nil {
+		return err
+	}
+
+	parent := result
+	for i, segment := range overlay.Namespace[:len(overlay.Namespace)-1] {
+		child, ok := parent[segment].(map[string]interface{})
+		if !ok {
+			if _, exists := parent[segment]; exists {
+				return fmt.Errorf("overlay %s can't merge into data namespace '%s', which is not a map", overlay.Path, strings.Join(overlay.Namespace[:i+1], "."))
+			}
+			child = make(map[string]interface{})
+			parent[segment] = child
+		}
+		parent = child
+	}
+
+	name := overlay.Namespace[len(overlay.Namespace)-1]
+	parent[name] = mergeData(parent[name], data)
+	if _, ok := sources[strings.Join(overlay.Namespace, ".")]; !ok {
+		sources[strings.Join(overlay.Namespace, ".")] = overlay.Path
+	}
+	return nil
+}
+
+// mergeData deep-merges overlay over base: maps are merged key by key, anything else
+// (lists included) is replaced
+func mergeData(base, overlay interface{}) interface{} {
+	baseMap, ok := base.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+	overlayMap, ok := overlay.(map[string]interface{})
+	if !ok {
+		return overlay
+	}
+
+	for key, value := range overlayMap {
+		baseMap[key] = mergeData(baseMap[key], value)
+	}
+	return baseMap
+}
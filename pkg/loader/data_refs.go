@@ -0,0 +1,137 @@
+package loader
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"genny/pkg/generator"
+)
+
+// refKey marks a data value that refers to another value: {$ref: authors.alice}
+const refKey = "$ref"
+
+// referenceResolver replaces $ref values in merged data with the values they point to
+type referenceResolver struct {
+	data    *generator.SimpleDataContext
+	sources map[string]string          // Dotted key → file that defined it
+	links   map[string]map[string]bool // Namespace → namespaces its references point into
+}
+
+// resolveReferences replaces every {$ref: path} map in the merged data with the value at
+// that data path. References may point at values that hold further references; a chain
+// that comes back to itself is an error. Errors name the file the reference is written in.
+// It returns, for each namespace holding references, the namespaces they point into,
+// since their values are copied into it.
+func resolveReferences(data map[string]interface{}, sources map[string]string) (map[string][]string, error) {
+	r := &referenceResolver{data: generator.NewSimpleDataContext(data), sources: sources, links: make(map[string]map[string]bool)}
+	for key, value := range data {
+		resolved, err := r.resolve(value, key, nil)
+		if err != nil {
+			return nil, err
+		}
+		data[key] = resolved
+	}
+
+	links := make(map[string][]string, len(r.links))
+	for namespace, targets := range r.links {
+		for target := range targets {
+			links[namespace] = append(links[namespace], target)
+		}
+		sort.Strings(links[namespace])
+	}
+	return links, nil
+}
+
+// resolve resolves the references in a value found at a dotted key. stack holds the
+// reference targets being resolved, to catch cycles.
+func (r *referenceResolver) resolve(value interface{}, key string, stack []string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if target, ok := refTarget(v); ok {
+			return r.follow(target, key, stack)
+		}
+		for childKey, child := range v {
+			resolved, err := r.resolve(child, key+"."+childKey, stack)
+			if err != nil {
+				return nil, err
+			}
+			v[childKey] = resolved
+		}
+		return v, nil
+
+	case []interface{}:
+		for i, child := range v {
+			resolved, err := r.resolve(child, key+"."+strconv.Itoa(i), stack)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// follow looks up a reference target and resolves the references inside it
+func (r *referenceResolver) follow(target, key string, stack []string) (interface{}, error) {
+	target = strings.TrimPrefix(target, ".")
+	for i, resolving := range stack {
+		if resolving == target {
+			chain := append(append([]string{}, stack[i:]...), target)
+			return nil, fmt.Errorf("%s: data reference cycle at '%s': %s", r.source(key), key, strings.Join(chain, " -> "))
+		}
+	}
+
+	value, err := r.data.Get(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: broken %s '%s' at '%s': %w", r.source(key), refKey, target, key, err)
+	}
+	r.link(key, target)
+	return r.resolve(value, target, append(stack, target))
+}
+
+// link records that the namespace of key holds a copy of data from the namespace of target
+func (r *referenceResolver) link(key, target string) {
+	namespace := rootKey(key)
+	if targetNamespace := rootKey(target); targetNamespace != namespace {
+		if r.links[namespace] == nil {
+			r.links[namespace] = make(map[string]bool)
+		}
+		r.links[namespace][targetNamespace] = true
+	}
+}
+
+// rootKey returns the first segment of a dotted key or data path
+func rootKey(key string) string {
+	root, _, _ := strings.Cut(key, ".")
+	root, _, _ = strings.Cut(root, "[")
+	return root
+}
+
+// source returns the file that defined a dotted key: the file of its closest namespace
+func (r *referenceResolver) source(key string) string {
+	for {
+		if source, ok := r.sources[key]; ok {
+			return source
+		}
+		dot := strings.LastIndex(key, ".")
+		if dot < 0 {
+			return "data"
+		}
+		key = key[:dot]
+	}
+}
+
+// refTarget returns the target of a {$ref: path} map. Any other key next to $ref makes it
+// an ordinary map.
+func refTarget(m map[string]interface{}) (string, bool) {
+	if len(m) != 1 {
+		return "", false
+	}
+	target, ok := m[refKey].(string)
+	return target, ok
+}
@@ -0,0 +1,43 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveReferencesLinksNamespaces(t *testing.T) {
+	data := map[string]interface{}{
+		"blog": map[string]interface{}{
+			"authors": map[string]interface{}{
+				"alice": map[string]interface{}{"company": map[string]interface{}{"$ref": "companies.acme"}},
+			},
+			"featured": map[string]interface{}{"$ref": "blog.authors.alice"},
+		},
+		"companies": map[string]interface{}{
+			"acme": map[string]interface{}{"name": "Acme", "owner": map[string]interface{}{"$ref": ".people.bob"}},
+		},
+		"people": map[string]interface{}{"bob": map[string]interface{}{"name": "Bob"}},
+	}
+
+	links, err := resolveReferences(data, map[string]string{})
+	if err != nil {
+		t.Fatalf("resolveReferences() error = %v", err)
+	}
+
+	// blog may also link to people directly, depending on which namespace is resolved
+	// first; either way people is reached through companies
+	if blog := links["blog"]; len(blog) == 0 || blog[0] != "companies" {
+		t.Errorf("resolveReferences() links[blog] = %v, want companies first", blog)
+	}
+	if want := []string{"people"}; !reflect.DeepEqual(links["companies"], want) {
+		t.Errorf("resolveReferences() links[companies] = %v, want %v", links["companies"], want)
+	}
+	if _, ok := links["people"]; ok {
+		t.Errorf("resolveReferences() links[people] = %v, want none", links["people"])
+	}
+
+	company := data["blog"].(map[string]interface{})["authors"].(map[string]interface{})["alice"].(map[string]interface{})["company"]
+	if name := company.(map[string]interface{})["name"]; name != "Acme" {
+		t.Errorf("blog.authors.alice.company.name = %v, want Acme", name)
+	}
+}
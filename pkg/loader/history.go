@@ -0,0 +1,80 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"genny/pkg/generator"
+	"genny/pkg/utils"
+)
+
+// History looks up the change history of project files from the local git repository,
+// falling back to file modification times
+type History struct {
+	root  string
+	files map[string]*generator.FileHistory // Root-relative slash path → git history
+}
+
+// LoadHistory reads the git history of every file under root with a single local
+// git log. Outside a repository, or without git installed, every lookup falls back to
+// the file's modification time.
+func (l *FileSystemLoader) LoadHistory(root string) *History {
+	h := &History{root: root, files: make(map[string]*generator.FileHistory)}
+
+	// Paths in the log are relative to the repository top, which may be above root
+	prefix, err := utils.Git(root, "rev-parse", "--show-prefix")
+	if err != nil {
+		return h
+	}
+	out, err := utils.Git(root, "-c", "core.quotepath=off", "log", "--no-renames", "--name-only", "--format=%x1e%aI%x1f%an", "--", ".")
+	if err != nil {
+		return h
+	}
+
+	// Commits are listed newest first: the first one seen for a file is its last change,
+	// the last one seen is its first commit
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		dateText, author, ok := strings.Cut(lines[0], "\x1f")
+		if !ok {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, dateText)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range lines[1:] {
+			name = strings.TrimSpace(name)
+			if name == "" || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			key := strings.TrimPrefix(name, prefix)
+			if file, seen := h.files[key]; seen {
+				file.Created = date
+				continue
+			}
+			h.files[key] = &generator.FileHistory{Updated: date, Author: author, Created: date, FromGit: true}
+		}
+	}
+
+	return h
+}
+
+// Of returns the history of a file. Files git doesn't know (untracked, or no repository)
+// use their modification time for both dates and have no author.
+func (h *History) Of(path string) *generator.FileHistory {
+	if rel, err := filepath.Rel(h.root, path); err == nil {
+		if file, ok := h.files[filepath.ToSlash(rel)]; ok {
+			return file
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return &generator.FileHistory{Updated: info.ModTime(), Created: info.ModTime()}
+}
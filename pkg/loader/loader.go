@@ -1,17 +1,22 @@
 // Package loader handles loading all project resources from the file system.
 // It provides interfaces and implementations for loading assets, data files,
-// components, and templates.
+// components, templates, pages, and the project configuration.
 package loader
 
-import "genny/pkg/generator"
+import (
+	"genny/pkg/config"
+	"genny/pkg/generator"
+)
 
 // Loader handles loading all project resources
 type Loader interface {
 	// LoadAssets discovers and loads all static assets
 	LoadAssets(root string) ([]generator.Asset, error)
 
-	// LoadData loads and merges all YAML data files
-	LoadData(root string) (map[string]interface{}, error)
+	// LoadData loads and merges all data files (YAML, JSON, TOML, CSV), validating them
+	// against their JSON Schemas and merging the active environment's overlay files over
+	// them. It also describes where the data came from.
+	LoadData(root string, options DataOptions) (map[string]interface{}, *DataSources, error)
 
 	// LoadComponents discovers and loads all component files
 	LoadComponents(root string) (map[string]*generator.Component, error)
@@ -21,6 +26,34 @@ type Loader interface {
 
 	// LoadPages discovers and loads all page files from subdirectories
 	LoadPages(root string) ([]*generator.Page, error)
+
+	// LoadPage loads a single page file to be written at outputPath
+	LoadPage(path string, outputPath string) (*generator.Page, error)
+
+	// LoadOutputTemplates discovers all non-page output templates (*.tmpl)
+	LoadOutputTemplates(root string) ([]*generator.OutputTemplate, error)
+
+	// LoadConfig loads the project configuration (genny.yaml)
+	LoadConfig(root string) (*config.Config, error)
+
+	// LoadHistory reads the change history of the project's files from local git
+	LoadHistory(root string) *History
+}
+
+// DataOptions controls how data files are loaded
+type DataOptions struct {
+	Schemas map[string]string // Data namespace → extra JSON Schema file
+	Env     string            // Active environment (e.g. "production"), empty for none
+
+	// Environments lists further environment names overlay files can target, besides
+	// the common ones and the active environment
+	Environments []string
+}
+
+// DataSources describes how loaded data was put together
+type DataSources struct {
+	Overlays []string            // Overlay files that were applied
+	Links    map[string][]string // Namespace → namespaces its $ref values were copied from
 }
 
 // FileSystemLoader implements Loader using the file system
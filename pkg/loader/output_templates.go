@@ -0,0 +1,62 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"genny/pkg/generator"
+)
+
+// LoadOutputTemplates discovers all *.tmpl files outside the special directories (components,
+// data, assets, www). Each is written at its path without the .tmpl extension, so
+// feeds/events.xml.tmpl becomes www/feeds/events.xml.
+func (l *FileSystemLoader) LoadOutputTemplates(root string) ([]*generator.OutputTemplate, error) {
+	var templates []*generator.OutputTemplate
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// Skip special directories and hidden ones like .git
+			switch {
+			case relPath == ".":
+				return nil
+			case relPath == "components" || relPath == "data" || relPath == "assets" || relPath == "www",
+				strings.HasPrefix(info.Name(), "."):
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(info.Name(), generator.OutputTemplateExt) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read output template %s: %w", path, err)
+		}
+
+		templates = append(templates, &generator.OutputTemplate{
+			SourcePath: path,
+			OutputPath: strings.TrimSuffix(relPath, generator.OutputTemplateExt),
+			Content:    string(content),
+		})
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover output templates: %w", err)
+	}
+
+	return templates, nil
+}
@@ -7,10 +7,15 @@ import (
 	"strings"
 
 	"genny/pkg/generator"
+	"genny/pkg/utils"
+
+	"github.com/toolvox/utilgo/pkg/serialization/yaml"
 )
 
-// LoadPages discovers all .html files at root level (excluding index.html, header.html, footer.html)
-// and index.html files in subdirectories (excluding components, data, assets, www)
+// LoadPages discovers all .html files at root level (excluding index.html, header.html, footer.html),
+// index.html files in subdirectories (excluding components, data, assets, www), and
+// collection templates named after their key (e.g. projects/[slug].html) anywhere.
+// A leading YAML front matter block is parsed into the page's Meta.
 func (l *FileSystemLoader) LoadPages(root string) ([]*generator.Page, error) {
 	var pages []*generator.Page
 
@@ -49,8 +54,8 @@ func (l *FileSystemLoader) LoadPages(root string) ([]*generator.Page, error) {
 			// Accept any .html file at root level (except index, header, footer)
 			// Output path stays the same
 		} else {
-			// For subdirectories: only process index.html files
-			if info.Name() != "index.html" {
+			// For subdirectories: only process index.html files and collection templates
+			if info.Name() != "index.html" && !generator.IsCollectionPath(info.Name()) {
 				return nil
 			}
 
@@ -63,17 +68,9 @@ func (l *FileSystemLoader) LoadPages(root string) ([]*generator.Page, error) {
 			}
 		}
 
-		// Read the page content
-		content, err := os.ReadFile(path)
+		page, err := l.LoadPage(path, relPath)
 		if err != nil {
-			return fmt.Errorf("failed to read page %s: %w", path, err)
-		}
-
-		// Create Page struct
-		page := &generator.Page{
-			SourcePath: path,
-			OutputPath: relPath,
-			Content:    string(content),
+			return err
 		}
 
 		pages = append(pages, page)
@@ -86,3 +83,31 @@ func (l *FileSystemLoader) LoadPages(root string) ([]*generator.Page, error) {
 
 	return pages, nil
 }
+
+// LoadPage reads a single page file, splitting off its front matter into the page's Meta
+func (l *FileSystemLoader) LoadPage(path string, outputPath string) (*generator.Page, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page %s: %w", path, err)
+	}
+
+	// Split off the front matter, if any
+	frontMatter, body := utils.SplitFrontMatter(string(content))
+	meta := make(map[string]interface{})
+	if strings.TrimSpace(frontMatter) != "" {
+		meta, err = yaml.Unmarshal[map[string]interface{}]([]byte(frontMatter))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse front matter in %s: %w", path, err)
+		}
+		if meta == nil {
+			meta = make(map[string]interface{})
+		}
+	}
+
+	return &generator.Page{
+		SourcePath: path,
+		OutputPath: outputPath,
+		Content:    body,
+		Meta:       meta,
+	}, nil
+}
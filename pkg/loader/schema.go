@@ -0,0 +1,199 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"genny/pkg/generator"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"gopkg.in/yaml.v3"
+)
+
+// schemaSuffix is the suffix of a data file's sibling JSON Schema (data/projects.schema.json)
+const schemaSuffix = ".schema.json"
+
+// schemaValidator validates data files against their JSON Schemas
+type schemaValidator struct {
+	root     string
+	mapping  map[string]string // Data namespace → schema path from the project config
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+// newSchemaValidator creates a schemaValidator for a project
+func newSchemaValidator(root string, mapping map[string]string) *schemaValidator {
+	return &schemaValidator{
+		root:     root,
+		mapping:  mapping,
+		compiler: jsonschema.NewCompiler(),
+		schemas:  make(map[string]*jsonschema.Schema),
+	}
+}
+
+// schemaPath finds the schema for a data file: the project config mapping for its
+// namespace takes precedence over a sibling *.schema.json file
+func (v *schemaValidator) schemaPath(path string, namespace []string) string {
+	if schema, ok := v.mapping[strings.Join(namespace, ".")]; ok {
+		return filepath.Join(v.root, schema)
+	}
+
+	sibling := strings.TrimSuffix(path, filepath.Ext(path)) + schemaSuffix
+	if _, err := os.Stat(sibling); err == nil {
+		return sibling
+	}
+	return ""
+}
+
+// Validate checks a data file's decoded content against its schema, if it has one,
+// returning every violation with its position in the file
+func (v *schemaValidator) Validate(path string, namespace []string, data interface{}) ([]generator.SchemaViolation, error) {
+	schemaPath := v.schemaPath(path, namespace)
+	if schemaPath == "" {
+		return nil, nil
+	}
+
+	schema, ok := v.schemas[schemaPath]
+	if !ok {
+		absPath, err := filepath.Abs(schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve schema %s: %w", schemaPath, err)
+		}
+		schema, err = v.compiler.Compile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema %s: %w", schemaPath, err)
+		}
+		v.schemas[schemaPath] = schema
+	}
+
+	err := schema.Validate(jsonCompatible(data))
+	if err == nil {
+		return nil, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, fmt.Errorf("failed to validate %s against %s: %w", path, schemaPath, err)
+	}
+
+	// Positions are available for formats the YAML parser can read (YAML and JSON)
+	var root *yaml.Node
+	if content, err := os.ReadFile(path); err == nil {
+		var doc yaml.Node
+		if yaml.Unmarshal(content, &doc) == nil && len(doc.Content) > 0 {
+			root = doc.Content[0]
+		}
+	}
+
+	var violations []generator.SchemaViolation
+	for _, leaf := range leafErrors(validationErr) {
+		violation := generator.SchemaViolation{
+			File:    path,
+			Path:    "/" + strings.Join(leaf.InstanceLocation, "/"),
+			Message: leaf.BasicOutput().Error.String(),
+		}
+		node := findNode(root, leaf.InstanceLocation)
+		// Point at the first unexpected key rather than its parent object
+		if extra, ok := leaf.ErrorKind.(*kind.AdditionalProperties); ok && len(extra.Properties) > 0 {
+			if key := findKey(node, extra.Properties[0]); key != nil {
+				node = key
+			}
+		}
+		if node != nil {
+			violation.Line = node.Line
+			violation.Column = node.Column
+		}
+		violations = append(violations, violation)
+	}
+
+	return violations, nil
+}
+
+// leafErrors returns the most specific errors of a validation error tree
+func leafErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, leafErrors(cause)...)
+	}
+	return leaves
+}
+
+// findNode walks a YAML node tree along a JSON pointer's tokens
+func findNode(node *yaml.Node, location []string) *yaml.Node {
+	for _, token := range location {
+		if node == nil {
+			return nil
+		}
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					next = node.Content[i+1]
+					break
+				}
+			}
+			node = next
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[index]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// findKey returns the key node of a mapping entry
+func findKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// jsonCompatible converts decoded data to values a JSON Schema can validate:
+// YAML timestamps become date or RFC 3339 strings
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = jsonCompatible(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = jsonCompatible(item)
+		}
+		return result
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return v
+	}
+}
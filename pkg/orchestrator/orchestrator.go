@@ -22,11 +22,11 @@ type Orchestrator struct {
 }
 
 // NewOrchestrator creates a new Orchestrator
-func NewOrchestrator(rootPath string, verbose bool) *Orchestrator {
+func NewOrchestrator(rootPath string, options site.Options) *Orchestrator {
 	return &Orchestrator{
-		site:    site.NewSite(rootPath, verbose),
+		site:    site.NewSite(rootPath, options),
 		watcher: watcher.NewFileWatcher(500 * time.Millisecond),
-		verbose: verbose,
+		verbose: options.Verbose,
 	}
 }
 
@@ -52,6 +52,23 @@ func (o *Orchestrator) RunOnce() error {
 	return nil
 }
 
+// RunCheck loads the site and renders it into a temporary directory, validating data,
+// pages and templates without writing any output
+func (o *Orchestrator) RunCheck() error {
+	log.Println("Checking site...")
+
+	if err := o.site.Load(); err != nil {
+		return fmt.Errorf("check failed: %w", err)
+	}
+
+	if err := o.site.Check(); err != nil {
+		return fmt.Errorf("check failed: %w", err)
+	}
+
+	log.Println("✓ Site check passed")
+	return nil
+}
+
 // RunContinuous runs in watch mode, regenerating on file changes
 func (o *Orchestrator) RunContinuous() error {
 	// Initial generation
@@ -74,11 +91,16 @@ func (o *Orchestrator) RunContinuous() error {
 		"./assets",
 	}
 
-	// Add all page files from subdirectories
+	// Add all page files from subdirectories, including held back drafts
 	if o.site.GetSite() != nil {
-		for _, page := range o.site.GetSite().Pages {
+		for _, page := range o.site.GetSite().AllPages() {
 			watchPaths = append(watchPaths, page.SourcePath)
 		}
+		for _, output := range o.site.GetSite().Outputs {
+			watchPaths = append(watchPaths, output.SourcePath)
+		}
+		// Files read by templates, such as include samples
+		watchPaths = append(watchPaths, o.site.GetSite().Dependencies()...)
 	}
 
 	// Create a channel for regeneration
@@ -126,7 +148,21 @@ func (o *Orchestrator) RunContinuous() error {
 			} else {
 				elapsed := time.Since(start)
 				log.Printf("[%s] ✓ Regenerated in %v", time.Now().Format("15:04:05"), elapsed)
+				o.watchDependencies()
 			}
 		}
 	}
 }
+
+// watchDependencies watches files the last build read that weren't watched yet (e.g. a
+// newly included sample)
+func (o *Orchestrator) watchDependencies() {
+	if o.site.GetSite() == nil {
+		return
+	}
+	for _, path := range o.site.GetSite().Dependencies() {
+		if err := o.watcher.Add(path); err != nil {
+			log.Printf("Warning: Could not watch %s: %v", path, err)
+		}
+	}
+}
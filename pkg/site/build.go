@@ -0,0 +1,48 @@
+package site
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"genny/pkg/generator"
+	"genny/pkg/utils"
+)
+
+// envNamePattern matches valid environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// loadEnv reads the allowlisted environment variables for the .Env namespace. Every
+// allowlisted name is present (empty when unset); nothing else is exposed.
+func loadEnv(allowlist []string) (map[string]string, error) {
+	env := make(map[string]string, len(allowlist))
+	for _, name := range allowlist {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name '%s' in env allowlist", name)
+		}
+		env[name] = os.Getenv(name)
+	}
+	return env, nil
+}
+
+// loadBuildInfo describes the current build for the .Build namespace. Git details are
+// left empty when the project isn't in a git repository.
+func loadBuildInfo(root, version string) *generator.BuildInfo {
+	info := &generator.BuildInfo{
+		Version: version,
+		Time:    time.Now(),
+	}
+
+	if commit, err := utils.Git(root, "rev-parse", "HEAD"); err == nil {
+		info.Commit = commit
+		if len(commit) > 7 {
+			info.ShortCommit = commit[:7]
+		}
+	}
+	if branch, err := utils.Git(root, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		info.Branch = branch
+	}
+
+	return info
+}
@@ -5,11 +5,15 @@ package site
 import (
 	"fmt"
 	"html/template"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"genny/pkg/config"
 	"genny/pkg/encrypt"
 	"genny/pkg/generator"
 	"genny/pkg/loader"
@@ -21,6 +25,7 @@ import (
 // Site encapsulates all site operations
 type Site struct {
 	rootPath    string
+	config      *config.Config
 	site        *generator.Site
 	loader      loader.Loader
 	parser      *parser.ComponentParser
@@ -40,16 +45,36 @@ type Site struct {
 	originalFooterContent      string
 
 	verbose bool
+	drafts  bool
+	strict  bool
+	check   bool
+	env     string
+	version string
+}
+
+// Options controls how the site is built
+type Options struct {
+	Verbose bool   // Enable verbose logging
+	Drafts  bool   // Publish drafts and pages scheduled for the future
+	Strict  bool   // Fail the build when a template reads missing data
+	Check   bool   // Only validate: write nothing to the project or the output directory
+	Env     string // Active environment, selecting data overlay files (e.g. "production")
+	Version string // genny version, exposed to templates as .Build.Version
 }
 
 // NewSite creates a new Site
-func NewSite(rootPath string, verbose bool) *Site {
+func NewSite(rootPath string, options Options) *Site {
 	return &Site{
 		rootPath:    rootPath,
 		loader:      loader.NewFileSystemLoader(),
-		parser:      parser.NewComponentParser(verbose),
+		parser:      parser.NewComponentParser(options.Verbose),
 		tagReplacer: parser.NewTagReplacer(),
-		verbose:     verbose,
+		verbose:     options.Verbose,
+		drafts:      options.Drafts,
+		strict:      options.Strict,
+		check:       options.Check,
+		env:         options.Env,
+		version:     options.Version,
 	}
 }
 
@@ -61,6 +86,13 @@ func (s *Site) Load() error {
 	}
 	log.Printf("Loading site from: %s", siteRootPath)
 
+	// Load project configuration
+	cfg, err := s.loader.LoadConfig(s.rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	s.config = cfg
+
 	// Load assets
 	assets, err := s.loader.LoadAssets(s.rootPath)
 	if err != nil {
@@ -69,15 +101,49 @@ func (s *Site) Load() error {
 	log.Printf("Loaded %d assets", len(assets))
 
 	// Load data
-	data, err := s.loader.LoadData(s.rootPath)
+	data, sources, err := s.loader.LoadData(s.rootPath, loader.DataOptions{
+		Schemas:      s.config.Schemas,
+		Env:          s.env,
+		Environments: s.config.Environments,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to load data: %w", err)
 	}
 	log.Printf("Loaded data")
+
 	if s.verbose {
+		if s.env != "" {
+			log.Printf("Environment: %s (%d data overlays)", s.env, len(sources.Overlays))
+		}
+		for _, overlay := range sources.Overlays {
+			log.Printf("Applied data overlay: %s", overlay)
+		}
 		log.Printf("data: %+v", data)
 	}
 
+	// Data namespaces must not be hidden by the keys genny adds to the template data
+	if err := generator.CheckReservedKeys(data); err != nil {
+		return fmt.Errorf("failed to load data: %w", err)
+	}
+
+	// Compute derived collections over the merged data
+	dataLinks := sources.Links
+	if err := s.deriveCollections(data, dataLinks); err != nil {
+		return err
+	}
+
+	// Read allowlisted environment variables
+	env, err := loadEnv(s.config.Env)
+	if err != nil {
+		return fmt.Errorf("failed to load environment: %w", err)
+	}
+
+	// Check the highlighting theme before generating anything
+	highlight, err := s.highlightOptions()
+	if err != nil {
+		return err
+	}
+
 	// Load components
 	components, err := s.loader.LoadComponents(s.rootPath)
 	if err != nil {
@@ -85,6 +151,12 @@ func (s *Site) Load() error {
 	}
 	log.Printf("Loaded %d components", len(components))
 
+	// Read file history from local git (or modification times)
+	history := s.loader.LoadHistory(s.rootPath)
+	for _, comp := range components {
+		comp.History = history.Of(comp.FilePath)
+	}
+
 	// Parse components
 	if err := s.parser.ParseComponents(components); err != nil {
 		return fmt.Errorf("failed to parse components: %w", err)
@@ -104,7 +176,44 @@ func (s *Site) Load() error {
 	if err != nil {
 		return fmt.Errorf("failed to load pages: %w", err)
 	}
+
+	// Expand collection templates into one page per data item
+	dataContext := generator.NewSimpleDataContext(data)
+	pages, err = generator.ExpandCollectionPages(pages, dataContext)
+	if err != nil {
+		return fmt.Errorf("failed to expand collection pages: %w", err)
+	}
+
+	// Split paginated pages into one page per chunk of items
+	pages, err = generator.ExpandPaginatedPages(pages, dataContext)
+	if err != nil {
+		return fmt.Errorf("failed to expand paginated pages: %w", err)
+	}
+
+	// Hold back drafts and scheduled pages (they still get previews)
+	pages, heldPages, err := generator.FilterPublished(pages, time.Now(), s.drafts)
+	if err != nil {
+		return fmt.Errorf("failed to check page publish state: %w", err)
+	}
+
+	// Build taxonomies from page front matter and generate their index pages
+	pages, taxonomies, err := s.buildTaxonomies(pages)
+	if err != nil {
+		return fmt.Errorf("failed to build taxonomies: %w", err)
+	}
+
+	// Build the page tree for menus and breadcrumbs
+	menu := generator.BuildMenu(pages, heldPages)
 	log.Printf("Loaded %d pages", len(pages))
+	if len(heldPages) > 0 {
+		log.Printf("Held back %d draft or scheduled pages", len(heldPages))
+	}
+
+	// Load non-page output templates (feeds, manifests...)
+	outputs, err := s.loader.LoadOutputTemplates(s.rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to load output templates: %w", err)
+	}
 
 	// Load templates
 	templates, err := s.loader.LoadTemplates(s.rootPath)
@@ -112,15 +221,22 @@ func (s *Site) Load() error {
 		return fmt.Errorf("failed to load templates: %w", err)
 	}
 
+	// Held back pages are processed like any other page, for their previews
+	allPages := make([]*generator.Page, 0, len(pages)+len(heldPages))
+	allPages = append(allPages, pages...)
+	allPages = append(allPages, heldPages...)
+
 	// Store original page content before wrapping and tag replacement
 	s.originalPageContent = make(map[string]string)
-	for _, page := range pages {
+	for _, page := range allPages {
 		s.originalPageContent[page.SourcePath] = page.Content
 	}
 
 	// Process pages - extract encrypt keys, wrap with header/footer, replace component tags
 	hasEncryptedPages := false
-	for _, page := range pages {
+	for _, page := range allPages {
+		page.History = history.Of(page.SourcePath)
+
 		// Extract encrypt key before wrapping (it's in the <head> section)
 		page.EncryptKey = s.tagReplacer.ExtractEncryptKey(page.Content)
 		if page.EncryptKey != "" {
@@ -140,7 +256,7 @@ func (s *Site) Load() error {
 	}
 
 	// Auto-create decrypt.html at site root if any page is encrypted and it doesn't exist
-	if hasEncryptedPages {
+	if hasEncryptedPages && !s.check {
 		decryptPath := filepath.Join(s.rootPath, "decrypt.html")
 		if _, err := os.Stat(decryptPath); os.IsNotExist(err) {
 			if err := os.WriteFile(decryptPath, []byte(encrypt.DefaultDecryptPageHTML), 0644); err != nil {
@@ -152,12 +268,26 @@ func (s *Site) Load() error {
 
 	// Create the Site struct
 	s.site = &generator.Site{
-		RootPath:   s.rootPath,
-		Assets:     assets,
-		Data:       generator.NewSimpleDataContext(data),
-		Components: components,
-		Pages:      pages,
-		Templates:  make(map[string]*template.Template),
+		RootPath:     s.rootPath,
+		Assets:       assets,
+		Data:         dataContext,
+		Components:   components,
+		Pages:        pages,
+		Outputs:      outputs,
+		Templates:    make(map[string]*template.Template),
+		Taxonomies:   taxonomies,
+		HeldPages:    heldPages,
+		Menu:         menu,
+		Environment:  s.env,
+		Env:          env,
+		Build:        loadBuildInfo(s.rootPath, s.version),
+		DataHistory:  s.dataHistory(history),
+		DataLinks:    dataLinks,
+		Highlight:    highlight,
+		SummaryWords: s.config.SummaryWords,
+		API:          apiOptions(s.config.API),
+		BaseURL:      s.config.BaseURL,
+		IndexHistory: history.Of(filepath.Join(s.rootPath, "index.html")),
 	}
 
 	// Parse index.html to create wrapper and main templates
@@ -172,7 +302,7 @@ func (s *Site) Load() error {
 		return fmt.Errorf("failed to extract wrapper: %w", err)
 	}
 
-	s.wrapperTemplate, err = template.New("Wrapper").Parse(wrapperContent)
+	s.wrapperTemplate, err = generator.NewTemplate("Wrapper", s.strict).Funcs(generator.PageFuncs(s.site, nil, true)).Parse(wrapperContent)
 	if err != nil {
 		return &generator.TemplateParseError{
 			Name:   "Wrapper",
@@ -212,20 +342,51 @@ func (s *Site) Generate() error {
 	}
 
 	log.Println("Generating site...")
+	if err := s.generate("./www"); err != nil {
+		return err
+	}
+
+	log.Println("Site generation complete!")
+	return nil
+}
 
+// Check renders the entire site into a temporary directory that is removed afterwards,
+// so every template is parsed and executed without touching the output directory
+func (s *Site) Check() error {
+	if s.site == nil {
+		return fmt.Errorf("site not loaded - call Load() first")
+	}
+
+	outputDir, err := os.MkdirTemp("", "genny-check-")
+	if err != nil {
+		return fmt.Errorf("failed to create check directory: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	log.Println("Rendering site...")
+	return s.generate(outputDir)
+}
+
+// generate renders the site and its previews into outputDir
+func (s *Site) generate(outputDir string) error {
 	// Track component usage
 	usedComponents := s.findUsedComponents()
 
+	// Render every page once for its headings and summary, so any page can read them
+	mainGen := generator.NewMainSiteGenerator(outputDir, s.strict)
+	if err := mainGen.ScanPages(s.site, s.headerContent, s.footerContent); err != nil {
+		return fmt.Errorf("failed to scan pages: %w", err)
+	}
+
 	// Generate component previews
-	previewDir := "./www/preview"
-	componentGen := generator.NewComponentGenerator(previewDir, s.verbose)
+	previewDir := filepath.Join(outputDir, "preview")
+	componentGen := generator.NewComponentGenerator(previewDir, s.verbose, s.strict)
 	if err := componentGen.GenerateComponentPreviews(s.site, s.wrapperTemplate); err != nil {
 		return fmt.Errorf("failed to generate component previews: %w", err)
 	}
 	log.Printf("Generated %d component previews", len(s.site.Components))
 
 	// Generate main site
-	mainGen := generator.NewMainSiteGenerator("./www")
 	if err := mainGen.GenerateMainSite(s.site, s.mainTemplateContent, s.headerContent, s.footerContent); err != nil {
 		return fmt.Errorf("failed to generate main site: %w", err)
 	}
@@ -237,6 +398,30 @@ func (s *Site) Generate() error {
 	}
 	log.Printf("Generated %d pages", len(s.site.Pages))
 
+	// Generate non-page outputs
+	if err := mainGen.GenerateOutputs(s.site); err != nil {
+		return fmt.Errorf("failed to generate outputs: %w", err)
+	}
+	if len(s.site.Outputs) > 0 {
+		log.Printf("Generated %d outputs", len(s.site.Outputs))
+	}
+
+	// Export allowlisted data namespaces as JSON
+	if err := mainGen.ExportAPI(s.site, s.site.API); err != nil {
+		return fmt.Errorf("failed to export API: %w", err)
+	}
+	if s.site.API != nil && len(s.site.API.Namespaces) > 0 {
+		log.Printf("Exported %d data namespaces to %s/", len(s.site.API.Namespaces), generator.APIDir)
+	}
+
+	// Generate sitemap.xml and robots.txt
+	if err := mainGen.GenerateSitemap(s.site); err != nil {
+		return fmt.Errorf("failed to generate sitemap: %w", err)
+	}
+	if s.site.BaseURL != "" {
+		log.Printf("Generated %s and %s", generator.SitemapFile, generator.RobotsFile)
+	}
+
 	// Generate page previews
 	if err := mainGen.GeneratePagePreviews(s.site, s.headerContent, s.footerContent, previewDir); err != nil {
 		return fmt.Errorf("failed to generate page previews: %w", err)
@@ -246,7 +431,7 @@ func (s *Site) Generate() error {
 	if err := mainGen.GenerateMainSitePreview(s.site, s.mainTemplateContent, s.headerContent, s.footerContent, previewDir); err != nil {
 		return fmt.Errorf("failed to generate main site preview: %w", err)
 	}
-	log.Printf("Generated %d page previews", len(s.site.Pages)+1)
+	log.Printf("Generated %d page previews", len(s.site.Pages)+len(s.site.HeldPages)+1)
 
 	// Copy assets
 	if err := mainGen.CopyAssets(s.site.Assets); err != nil {
@@ -255,7 +440,7 @@ func (s *Site) Generate() error {
 	log.Printf("Copied %d assets", len(s.site.Assets))
 
 	// Copy stylesheet
-	if err := mainGen.CopyStylesheet(s.rootPath); err != nil {
+	if err := mainGen.CopyStylesheet(s.rootPath, s.site.Highlight); err != nil {
 		return fmt.Errorf("failed to copy stylesheet: %w", err)
 	}
 	log.Println("Copied stylesheet")
@@ -263,10 +448,184 @@ func (s *Site) Generate() error {
 	// Report unused components
 	s.reportUnusedComponents(usedComponents)
 
-	log.Println("Site generation complete!")
+	// Report drafts and scheduled pages that were not published
+	s.reportHeldPages()
+
 	return nil
 }
 
+// dataHistory looks up the history of every file in the data directory, keyed by its
+// path relative to the project root (e.g. "data/projects.yaml")
+func (s *Site) dataHistory(history *loader.History) map[string]*generator.FileHistory {
+	result := make(map[string]*generator.FileHistory)
+	filepath.WalkDir(filepath.Join(s.rootPath, "data"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(s.rootPath, path); err == nil {
+			result[filepath.ToSlash(rel)] = history.Of(path)
+		}
+		return nil
+	})
+	return result
+}
+
+// highlightOptions returns the syntax highlighting settings from genny.yaml, or nil when
+// highlighting is disabled
+func (s *Site) highlightOptions() (*generator.HighlightOptions, error) {
+	if s.config.Highlight == nil {
+		return nil, nil
+	}
+
+	options := &generator.HighlightOptions{
+		Theme:       s.config.Highlight.Theme,
+		LineNumbers: s.config.Highlight.LineNumbers,
+	}
+	if options.Theme == "" {
+		options.Theme = generator.DefaultHighlightTheme
+	}
+	if err := generator.CheckHighlightTheme(options.Theme); err != nil {
+		return nil, fmt.Errorf("invalid highlight settings in %s: %w", config.FileName, err)
+	}
+	return options, nil
+}
+
+// apiOptions converts the API export settings from genny.yaml, nil when there are none
+func apiOptions(api *config.API) *generator.APIOptions {
+	if api == nil {
+		return nil
+	}
+	return &generator.APIOptions{Namespaces: api.Namespaces, Pretty: api.Pretty}
+}
+
+// deriveCollections computes the collections declared in genny.yaml and stores them under
+// the derived namespace, in name order. Each collection is linked to the namespace it is
+// taken from in links.
+func (s *Site) deriveCollections(data map[string]interface{}, links map[string][]string) error {
+	if len(s.config.Collections) == 0 {
+		return nil
+	}
+	if _, exists := data[generator.DerivedNamespace]; exists {
+		return fmt.Errorf("data namespace '%s' is reserved for derived collections", generator.DerivedNamespace)
+	}
+
+	names := make([]string, 0, len(s.config.Collections))
+	for name := range s.config.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dataContext := generator.NewSimpleDataContext(data)
+	derived := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		c := s.config.Collections[name]
+		items, err := generator.DeriveCollection(dataContext, generator.CollectionQuery{
+			From:    c.From,
+			Filter:  c.Filter,
+			Sort:    c.Sort,
+			Reverse: c.Reverse,
+			Limit:   c.Limit,
+			GroupBy: c.GroupBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to derive collection '%s': %w", name, err)
+		}
+		derived[name] = items
+		source, _, _ := strings.Cut(strings.TrimPrefix(c.From, "."), ".")
+		source, _, _ = strings.Cut(source, "[")
+		links[generator.DerivedNamespace+"."+name] = []string{source}
+		if s.verbose {
+			log.Printf("Derived collection %s: %d entries", name, len(items))
+		}
+	}
+
+	data[generator.DerivedNamespace] = derived
+	return nil
+}
+
+// buildTaxonomies builds every taxonomy configured in genny.yaml from the pages' front matter
+// and appends the taxonomy index pages rendered from the configured templates. Template
+// files discovered as regular pages are removed from the page list.
+func (s *Site) buildTaxonomies(pages []*generator.Page) ([]*generator.Page, map[string]*generator.Taxonomy, error) {
+	taxonomies := make(map[string]*generator.Taxonomy)
+	if len(s.config.Taxonomies) == 0 {
+		return pages, taxonomies, nil
+	}
+
+	// Template files are not pages of their own
+	templatePaths := make(map[string]bool)
+	for _, tc := range s.config.Taxonomies {
+		for _, tmpl := range []string{tc.ListTemplate, tc.TermTemplate} {
+			if tmpl != "" {
+				templatePaths[filepath.Clean(filepath.Join(s.rootPath, tmpl))] = true
+			}
+		}
+	}
+	var contentPages []*generator.Page
+	for _, page := range pages {
+		if !templatePaths[filepath.Clean(page.SourcePath)] {
+			contentPages = append(contentPages, page)
+		}
+	}
+
+	names := make([]string, 0, len(s.config.Taxonomies))
+	for name := range s.config.Taxonomies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Taxonomy pages must not overwrite pages, collection items or each other
+	seen := make(map[string]string, len(contentPages))
+	for _, page := range contentPages {
+		seen[filepath.Clean(page.OutputPath)] = page.SourcePath
+	}
+
+	result := contentPages
+	for _, name := range names {
+		tc := s.config.Taxonomies[name]
+		dir := tc.Path
+		if dir == "" {
+			dir = name
+		}
+		dir = filepath.Clean(strings.Trim(dir, "/"))
+
+		taxonomy := generator.BuildTaxonomy(name, dir, contentPages)
+		taxonomies[name] = taxonomy
+		log.Printf("Taxonomy %s has %d terms", name, len(taxonomy.Terms))
+
+		listTemplate, err := s.loadTaxonomyTemplate(tc.ListTemplate)
+		if err != nil {
+			return nil, nil, err
+		}
+		termTemplate, err := s.loadTaxonomyTemplate(tc.TermTemplate)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, page := range generator.TaxonomyPages(taxonomy, dir, listTemplate, termTemplate) {
+			outputPath := filepath.Clean(page.OutputPath)
+			if source, exists := seen[outputPath]; exists {
+				return nil, nil, &generator.ValidationError{
+					Field:   "taxonomies." + name,
+					Message: fmt.Sprintf("output path %s is also generated from %s", page.OutputPath, source),
+				}
+			}
+			seen[outputPath] = page.SourcePath
+			result = append(result, page)
+		}
+	}
+
+	return result, taxonomies, nil
+}
+
+// loadTaxonomyTemplate loads a taxonomy page template, returning nil if none is configured
+func (s *Site) loadTaxonomyTemplate(path string) (*generator.Page, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return s.loader.LoadPage(filepath.Join(s.rootPath, path), path)
+}
+
 // GetSite returns the underlying Site struct
 func (s *Site) GetSite() *generator.Site {
 	return s.site
@@ -291,8 +650,8 @@ func (s *Site) findUsedComponents() map[string]bool {
 		}
 	}
 
-	// Track components used in pages (use original content)
-	for _, page := range s.site.Pages {
+	// Track components used in pages, including held back ones (use original content)
+	for _, page := range s.site.AllPages() {
 		originalContent := s.originalPageContent[page.SourcePath]
 		for name := range s.site.Components {
 			if s.isComponentUsedInContent(name, originalContent) {
@@ -320,10 +679,11 @@ func (s *Site) findUsedComponents() map[string]bool {
 	return used
 }
 
-// isComponentUsedInContent checks if a component tag appears in content
+// isComponentUsedInContent checks if a component tag, or a table of contents rendered
+// with the component, appears in content
 func (s *Site) isComponentUsedInContent(componentName, content string) bool {
 	openTag := fmt.Sprintf("<%s>", componentName)
-	return strings.Contains(content, openTag)
+	return strings.Contains(content, openTag) || strings.Contains(content, fmt.Sprintf("toc %q", componentName))
 }
 
 // reportUnusedComponents logs unused components
@@ -344,3 +704,18 @@ func (s *Site) reportUnusedComponents(used map[string]bool) {
 		log.Println()
 	}
 }
+
+// reportHeldPages logs the drafts and scheduled pages that were held back from the output
+func (s *Site) reportHeldPages() {
+	if len(s.site.HeldPages) == 0 {
+		return
+	}
+
+	now := time.Now()
+	log.Println()
+	log.Println("⚠ Held back from publishing (previews only, use -drafts to include):")
+	for _, page := range s.site.HeldPages {
+		log.Printf("  - %s (%s)", page.SourcePath, page.HoldReason(now))
+	}
+	log.Println()
+}
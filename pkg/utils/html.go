@@ -17,8 +17,14 @@ func ExtractBodyContent(filePath string) (string, error) {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
+	return ExtractBody(string(data))
+}
+
+// ExtractBody returns the content of the <body> tag of an HTML document as a string.
+// It returns an error if no body tag is found.
+func ExtractBody(data string) (string, error) {
 	// Parse the HTML
-	doc, err := html.Parse(strings.NewReader(string(data)))
+	doc, err := html.Parse(strings.NewReader(data))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse HTML: %w", err)
 	}
@@ -122,21 +128,58 @@ func ExtractTemplatesAndBody(filePath string) (string, string, error) {
 }
 
 // CleanupWhitespace removes excessive newlines from HTML content
-// It removes all blank lines that appear between tags
+// It removes all blank lines that appear between tags, keeping those inside <pre> blocks
 func CleanupWhitespace(content string) string {
 	lines := strings.Split(content, "\n")
 	var result []string
+	inPre := false
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
 		// Skip completely empty lines
-		if trimmed == "" {
+		if trimmed == "" && !inPre {
 			continue
 		}
 
+		lower := strings.ToLower(line)
+		if open := max(strings.LastIndex(lower, "<pre>"), strings.LastIndex(lower, "<pre ")); open >= 0 || strings.Contains(lower, "</pre>") {
+			inPre = open > strings.LastIndex(lower, "</pre>")
+		}
+
 		result = append(result, line)
 	}
 
 	return strings.Join(result, "\n")
 }
+
+// SplitFrontMatter separates a leading YAML front matter block (delimited by "---" lines)
+// from the rest of the content. If the content has no front matter, the returned
+// front matter is empty and the body is the original content.
+func SplitFrontMatter(content string) (string, string) {
+	trimmed := strings.TrimLeft(content, "\ufeff \t\r\n")
+	if !strings.HasPrefix(trimmed, "---\n") && !strings.HasPrefix(trimmed, "---\r\n") {
+		return "", content
+	}
+
+	rest := trimmed[strings.Index(trimmed, "\n")+1:]
+	for offset := 0; offset < len(rest); {
+		lineEnd := strings.Index(rest[offset:], "\n")
+		if lineEnd == -1 {
+			lineEnd = len(rest) - offset
+		}
+		line := strings.TrimRight(rest[offset:offset+lineEnd], "\r")
+		if line == "---" {
+			frontMatter := rest[:offset]
+			body := ""
+			if offset+lineEnd < len(rest) {
+				body = rest[offset+lineEnd+1:]
+			}
+			return frontMatter, body
+		}
+		offset += lineEnd + 1
+	}
+
+	// No closing delimiter - treat the whole file as content
+	return "", content
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// WithinDir reports whether path is dir or inside it, both as written and after
+// following symlinks, so a link can't lead outside dir either. A path that doesn't
+// exist is only checked as written.
+func WithinDir(dir, path string) (bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if !insideDir(dir, path) {
+		return false, nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return true, nil
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	return insideDir(realDir, resolved), nil
+}
+
+// insideDir reports whether path is dir or inside it, comparing the paths as written
+func insideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -3,9 +3,11 @@
 package watcher
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -16,6 +18,9 @@ type Watcher interface {
 	// Watch starts watching the specified paths and calls onChange when changes are detected
 	Watch(paths []string, onChange func(path string)) error
 
+	// Add starts watching another file while the watcher runs
+	Add(path string) error
+
 	// Stop stops the watcher
 	Stop() error
 }
@@ -24,6 +29,7 @@ type Watcher interface {
 type FileWatcher struct {
 	debounceInterval time.Duration
 	stopChan         chan bool
+	mu               sync.Mutex // Guards watcher, which Add uses from other goroutines
 	watcher          *fsnotify.Watcher
 	debounceTimer    *time.Timer
 	pendingChanges   map[string]bool
@@ -40,12 +46,19 @@ func NewFileWatcher(debounceInterval time.Duration) *FileWatcher {
 
 // Watch starts watching the specified paths and calls onChange when changes are detected
 func (w *FileWatcher) Watch(paths []string, onChange func(path string)) error {
-	var err error
-	w.watcher, err = fsnotify.NewWatcher()
+	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
 	}
-	defer w.watcher.Close()
+	w.mu.Lock()
+	w.watcher = watcher
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.watcher = nil
+		w.mu.Unlock()
+		watcher.Close()
+	}()
 
 	// Add paths to watcher
 	for _, path := range paths {
@@ -59,7 +72,7 @@ func (w *FileWatcher) Watch(paths []string, onChange func(path string)) error {
 		}
 
 		// Add to watcher
-		if err := w.watcher.Add(path); err != nil {
+		if err := watcher.Add(path); err != nil {
 			log.Printf("Warning: Could not watch %s: %v", path, err)
 			continue
 		}
@@ -76,7 +89,7 @@ func (w *FileWatcher) Watch(paths []string, onChange func(path string)) error {
 			for _, entry := range entries {
 				if !entry.IsDir() {
 					fullPath := filepath.Join(path, entry.Name())
-					if err := w.watcher.Add(fullPath); err != nil {
+					if err := watcher.Add(fullPath); err != nil {
 						log.Printf("Warning: Could not watch %s: %v", fullPath, err)
 					}
 				}
@@ -87,7 +100,7 @@ func (w *FileWatcher) Watch(paths []string, onChange func(path string)) error {
 	// Main watch loop
 	for {
 		select {
-		case event, ok := <-w.watcher.Events:
+		case event, ok := <-watcher.Events:
 			if !ok {
 				return nil
 			}
@@ -112,7 +125,7 @@ func (w *FileWatcher) Watch(paths []string, onChange func(path string)) error {
 				})
 			}
 
-		case err, ok := <-w.watcher.Errors:
+		case err, ok := <-watcher.Errors:
 			if !ok {
 				return nil
 			}
@@ -127,6 +140,16 @@ func (w *FileWatcher) Watch(paths []string, onChange func(path string)) error {
 	}
 }
 
+// Add starts watching another file while the watcher runs
+func (w *FileWatcher) Add(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watcher == nil {
+		return fmt.Errorf("watcher is not running")
+	}
+	return w.watcher.Add(path)
+}
+
 // Stop stops the watcher
 func (w *FileWatcher) Stop() error {
 	close(w.stopChan)